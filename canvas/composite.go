@@ -0,0 +1,131 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import "github.com/torbenschinke/rtc/math"
+
+// A CompositeOp is a Porter-Duff operator, which defines how much of the source and of the destination
+// remains, depending on their coverage (alpha).
+type CompositeOp int
+
+const (
+	// Over places the source on top of the destination.
+	Over CompositeOp = iota
+	// In keeps the source only where the destination is opaque and removes the destination.
+	In
+	// Out keeps the source only where the destination is transparent and removes the destination.
+	Out
+	// Atop places the source on top of the destination, but only where the destination is opaque.
+	Atop
+	// Xor keeps the source and the destination only where they do not overlap.
+	Xor
+)
+
+// A BlendMode defines the color where the source and the destination overlap, before the CompositeOp
+// is applied.
+type BlendMode int
+
+const (
+	// BlendNormal uses the source color.
+	BlendNormal BlendMode = iota
+	// BlendMultiply multiplies the colors, which always darkens.
+	BlendMultiply
+	// BlendScreen inverts, multiplies and inverts the colors again, which always brightens.
+	BlendScreen
+	// BlendOverlay multiplies the dark and screens the bright colors of the destination.
+	BlendOverlay
+	// BlendAdd sums up the colors, like two light sources. The result is not clamped.
+	BlendAdd
+)
+
+// Composite combines the source color into the destination color. Both have straight (not premultiplied)
+// alpha, like the canvas, but the operator is computed with premultiplied alpha. Blending follows the
+// W3C compositing specification, so the blend mode only affects the overlapping part.
+func Composite(dst, src *math.Vec4f, op CompositeOp, mode BlendMode) {
+	as, ab := src.W, dst.W
+
+	// the source color is mixed with the blend result, as far as the destination covers it
+	s := *src
+	if mode != BlendNormal {
+		s.X = (1-ab)*s.X + ab*blend(mode, dst.X, s.X)
+		s.Y = (1-ab)*s.Y + ab*blend(mode, dst.Y, s.Y)
+		s.Z = (1-ab)*s.Z + ab*blend(mode, dst.Z, s.Z)
+	}
+
+	s.Premultiply()
+	d := *dst
+	d.Premultiply()
+
+	var fa, fb float32
+	switch op {
+	case Over:
+		fa, fb = 1, 1-as
+	case In:
+		fa, fb = ab, 0
+	case Out:
+		fa, fb = 1-ab, 0
+	case Atop:
+		fa, fb = ab, 1-as
+	case Xor:
+		fa, fb = 1-ab, 1-as
+	}
+
+	s.Mul(fa)
+	d.Mul(fb)
+	s.Add(&d)
+	s.Unpremultiply()
+	*dst = s
+}
+
+// blend returns the mixed color of the destination b and the source s.
+func blend(mode BlendMode, b, s float32) float32 {
+	switch mode {
+	case BlendMultiply:
+		return b * s
+	case BlendScreen:
+		return b + s - b*s
+	case BlendOverlay:
+		if b <= 0.5 {
+			return 2 * b * s
+		}
+
+		return blend(BlendScreen, 2*b-1, s)
+	case BlendAdd:
+		return b + s
+	default:
+		return s
+	}
+}
+
+// Draw composites the source canvas onto this canvas, with the top left corner of the source at x, y,
+// which may be outside of this canvas. Only the overlapping pixels are changed, even for operators like In,
+// which would clear the destination outside of the source. Use 0, 0 to combine canvases of equal size.
+func (c *Canvas) Draw(src *Canvas, x, y int, op CompositeOp, mode BlendMode) {
+	for sy := 0; sy < src.Height; sy++ {
+		dy := y + sy
+		if dy < 0 || dy >= c.Height {
+			continue
+		}
+
+		for sx := 0; sx < src.Width; sx++ {
+			dx := x + sx
+			if dx < 0 || dx >= c.Width {
+				continue
+			}
+
+			Composite(c.Read(dx, dy), src.Read(sx, sy), op, mode)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestComposite(t *testing.T) {
+	red := math.NewRGBA(1, 0, 0, 1)
+	halfRed := math.NewRGBA(1, 0, 0, 0.5)
+	blue := math.NewRGBA(0, 0, 1, 1)
+	halfBlue := math.NewRGBA(0, 0, 1, 0.5)
+	transparent := math.NewRGBA(0, 0, 0, 0)
+
+	tests := []struct {
+		dst, src math.Vec4f
+		op       CompositeOp
+		mode     BlendMode
+		want     math.Vec4f
+	}{
+		{blue, red, Over, BlendNormal, red},
+		{blue, halfRed, Over, BlendNormal, math.NewRGBA(0.5, 0, 0.5, 1)},
+		{transparent, halfRed, Over, BlendNormal, halfRed},
+		{halfBlue, halfRed, Over, BlendNormal, math.NewRGBA(2.0/3, 0, 1.0/3, 0.75)},
+		{halfBlue, red, In, BlendNormal, halfRed},
+		{transparent, red, In, BlendNormal, transparent},
+		{halfBlue, red, Out, BlendNormal, halfRed},
+		{blue, red, Out, BlendNormal, transparent},
+		{blue, halfRed, Atop, BlendNormal, math.NewRGBA(0.5, 0, 0.5, 1)},
+		{transparent, red, Atop, BlendNormal, transparent},
+		{blue, red, Xor, BlendNormal, transparent},
+		{transparent, red, Xor, BlendNormal, red},
+		{halfBlue, halfRed, Xor, BlendNormal, math.NewRGBA(0.5, 0, 0.5, 0.5)},
+		{math.NewRGBA(0.5, 0.5, 1, 1), math.NewRGBA(0.5, 1, 0, 1), Over, BlendMultiply, math.NewRGBA(0.25, 0.5, 0, 1)},
+		{math.NewRGBA(0.5, 0.5, 1, 1), math.NewRGBA(0.5, 1, 0, 1), Over, BlendScreen, math.NewRGBA(0.75, 1, 1, 1)},
+		{math.NewRGBA(0.25, 0.75, 1, 1), math.NewRGBA(0.5, 0.5, 0.5, 1), Over, BlendOverlay, math.NewRGBA(0.25, 0.75, 1, 1)},
+		{math.NewRGBA(0.5, 1, 2, 1), math.NewRGBA(1, 1, 1, 1), Over, BlendAdd, math.NewRGBA(1.5, 2, 3, 1)},
+		{math.NewRGBA(0.5, 0.5, 0.5, 0), math.NewRGBA(1, 1, 1, 1), Over, BlendMultiply, math.NewRGBA(1, 1, 1, 1)},
+		{math.NewRGBA(0.5, 0.5, 0.5, 0.5), math.NewRGBA(1, 1, 1, 1), Over, BlendMultiply, math.NewRGBA(0.75, 0.75, 0.75, 1)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := tt.dst
+			Composite(&got, &tt.src, tt.op, tt.mode)
+			if !got.Equals(&tt.want) {
+				t.Errorf("Composite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanvas_Draw(t *testing.T) {
+	blue := math.NewRGBA(0, 0, 1, 1)
+	red := math.NewRGBA(1, 0, 0, 1)
+	c := NewCanvas(3, 2)
+	c.Clear(blue)
+	src := NewCanvas(2, 2)
+	src.Clear(red)
+
+	// only the bottom left pixel of the source overlaps
+	c.Draw(&src, 2, -1, Over, BlendNormal)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			want := blue
+			if x == 2 && y == 0 {
+				want = red
+			}
+
+			if got := *c.Read(x, y); got != want {
+				t.Errorf("Read(%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// canvases of equal size
+	dst := NewCanvas(2, 2)
+	dst.Clear(blue)
+	dst.Draw(&src, 0, 0, Xor, BlendNormal)
+	for i, v := range dst.Buffer {
+		if want := math.NewRGBA(0, 0, 0, 0); v != want {
+			t.Errorf("Buffer[%d] = %v, want %v", i, v, want)
+		}
+	}
+}
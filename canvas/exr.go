@@ -0,0 +1,770 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/torbenschinke/rtc/math"
+	"io"
+	"io/ioutil"
+	stdmath "math"
+	"sort"
+	"strings"
+)
+
+// EXRPixelType is the data type of the channel values.
+type EXRPixelType int32
+
+const (
+	// EXRHalf stores 16 bit floats, which is sufficient for colors and halves the size.
+	EXRHalf EXRPixelType = 1
+	// EXRFloat stores 32 bit floats, which keeps the values of the canvas exactly.
+	EXRFloat EXRPixelType = 2
+	// exrUint stores 32 bit unsigned integers, which are only read.
+	exrUint EXRPixelType = 0
+)
+
+// EXRCompression selects how the scanlines are compressed.
+type EXRCompression uint8
+
+const (
+	// EXRNoCompression stores the raw values.
+	EXRNoCompression EXRCompression = 0
+	// EXRZIPSCompression compresses each scanline separately with zlib.
+	EXRZIPSCompression EXRCompression = 2
+	// EXRZIPCompression compresses blocks of 16 scanlines with zlib, which usually compresses better.
+	EXRZIPCompression EXRCompression = 3
+)
+
+const (
+	exrMagic           = 20000630
+	exrVersion         = 2
+	exrLongNamesFlag   = 0x400
+	exrUnsupportedMask = 0x200 | 0x800 | 0x1000 // tiled, deep and multi part files
+	exrShortNameLimit  = 31
+	exrMaxZIPRatio     = 1032 // the best possible deflate compression
+)
+
+// An EXRLayer is a named set of channels, like the normals or the albedo of a render. Its channels are
+// stored as e.g. "normal.R" and "normal.G".
+type EXRLayer struct {
+	Name     string
+	Canvas   *Canvas // must have the same size as the exported canvas
+	Channels string  // a subset of "RGBA", all if empty
+}
+
+// EXROptions configures the OpenEXR export. The zero value is invalid, because the pixel type is required.
+type EXROptions struct {
+	PixelType   EXRPixelType
+	Compression EXRCompression
+	Layers      []EXRLayer // additional layers, besides the default layer from the canvas
+}
+
+// exrChannel describes a channel and the component of the canvas, which provides its values.
+type exrChannel struct {
+	name      string
+	pixelType EXRPixelType
+	canvas    *Canvas
+	component int // 0 to 3 for r, g, b and a
+}
+
+func (ch *exrChannel) size() int {
+	if ch.pixelType == EXRHalf {
+		return 2
+	}
+
+	return 4
+}
+
+// ExportEXR writes the buffer as a single part scanline OpenEXR image. The canvas becomes the default layer
+// with the channels R, G, B and A. The values are neither clamped nor converted, so they should be linear.
+func (c *Canvas) ExportEXR(w io.Writer, opts EXROptions) error {
+	if opts.PixelType != EXRHalf && opts.PixelType != EXRFloat {
+		return fmt.Errorf("canvas: unsupported exr pixel type %d", opts.PixelType)
+	}
+
+	linesPerBlock := exrLinesPerBlock(opts.Compression)
+	if linesPerBlock == 0 {
+		return fmt.Errorf("canvas: unsupported exr compression %d", opts.Compression)
+	}
+
+	if c.Width < 1 || c.Height < 1 {
+		return fmt.Errorf("canvas: cannot export an empty canvas as exr")
+	}
+
+	channels, err := c.exrChannels(&opts)
+	if err != nil {
+		return err
+	}
+
+	header := &exrBuffer{}
+	header.uint32(exrMagic)
+	version := uint32(exrVersion)
+	for _, ch := range channels {
+		if len(ch.name) > exrShortNameLimit {
+			version |= exrLongNamesFlag
+		}
+	}
+
+	header.uint32(version)
+
+	chlist := &exrBuffer{}
+	for _, ch := range channels {
+		chlist.str(ch.name)
+		chlist.uint32(uint32(ch.pixelType))
+		chlist.Write([]byte{0, 0, 0, 0}) // pLinear and reserved
+		chlist.uint32(1)                 // x sampling
+		chlist.uint32(1)                 // y sampling
+	}
+
+	chlist.WriteByte(0)
+
+	window := &exrBuffer{}
+	for _, v := range []int{0, 0, c.Width - 1, c.Height - 1} {
+		window.uint32(uint32(v))
+	}
+
+	header.attribute("channels", "chlist", chlist.Bytes())
+	header.attribute("compression", "compression", []byte{byte(opts.Compression)})
+	header.attribute("dataWindow", "box2i", window.Bytes())
+	header.attribute("displayWindow", "box2i", window.Bytes())
+	header.attribute("lineOrder", "lineOrder", []byte{0}) // increasing y
+	header.attribute("pixelAspectRatio", "float", exrFloat(1))
+	header.attribute("screenWindowCenter", "v2f", append(exrFloat(0), exrFloat(0)...))
+	header.attribute("screenWindowWidth", "float", exrFloat(1))
+	header.WriteByte(0)
+
+	// the chunks are prepared in advance, because the offset table precedes them
+	blocks := (c.Height + linesPerBlock - 1) / linesPerBlock
+	chunks := &exrBuffer{}
+	offsets := make([]uint64, blocks)
+	offsetBase := uint64(header.Len() + 8*blocks)
+	raw := &exrBuffer{}
+	for b := 0; b < blocks; b++ {
+		y0 := b * linesPerBlock
+		y1 := y0 + linesPerBlock
+		if y1 > c.Height {
+			y1 = c.Height
+		}
+
+		raw.Reset()
+		for y := y0; y < y1; y++ {
+			for _, ch := range channels {
+				for x := 0; x < c.Width; x++ {
+					v := exrComponent(ch.canvas.Read(x, y), ch.component)
+					if ch.pixelType == EXRHalf {
+						raw.uint16(float32ToHalf(v))
+					} else {
+						raw.uint32(stdmath.Float32bits(v))
+					}
+				}
+			}
+		}
+
+		data := raw.Bytes()
+		if opts.Compression != EXRNoCompression {
+			compressed, err := exrCompressZIP(data)
+			if err != nil {
+				return err
+			}
+
+			// the data is stored raw, if compression does not pay off
+			if len(compressed) < len(data) {
+				data = compressed
+			}
+		}
+
+		offsets[b] = offsetBase + uint64(chunks.Len())
+		chunks.uint32(uint32(y0))
+		chunks.uint32(uint32(len(data)))
+		chunks.Write(data)
+	}
+
+	for _, o := range offsets {
+		header.uint64(o)
+	}
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	_, err = w.Write(chunks.Bytes())
+	return err
+}
+
+// exrChannels returns the channels of the canvas and of all layers in the sorted order, which is
+// required by the format.
+func (c *Canvas) exrChannels(opts *EXROptions) ([]exrChannel, error) {
+	var channels []exrChannel
+	add := func(prefix, letters string, canvas *Canvas) error {
+		if letters == "" {
+			letters = "RGBA"
+		}
+
+		for _, l := range letters {
+			component := strings.IndexRune("RGBA", l)
+			if component < 0 {
+				return fmt.Errorf("canvas: invalid exr channel %q", l)
+			}
+
+			channels = append(channels, exrChannel{
+				name:      prefix + string(l),
+				pixelType: opts.PixelType,
+				canvas:    canvas,
+				component: component,
+			})
+		}
+
+		return nil
+	}
+
+	if err := add("", "RGBA", c); err != nil {
+		return nil, err
+	}
+
+	for _, l := range opts.Layers {
+		if l.Name == "" || strings.ContainsRune(l.Name, 0) {
+			return nil, fmt.Errorf("canvas: invalid exr layer name %q", l.Name)
+		}
+
+		if l.Canvas == nil || l.Canvas.Width != c.Width || l.Canvas.Height != c.Height {
+			return nil, fmt.Errorf("canvas: exr layer %q must have the size %dx%d", l.Name, c.Width, c.Height)
+		}
+
+		if err := add(l.Name+".", l.Channels, l.Canvas); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].name < channels[j].name
+	})
+
+	for i := 1; i < len(channels); i++ {
+		if channels[i].name == channels[i-1].name {
+			return nil, fmt.Errorf("canvas: duplicate exr channel %q", channels[i].name)
+		}
+	}
+
+	return channels, nil
+}
+
+func exrComponent(v *math.Vec4f, component int) float32 {
+	switch component {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	case 2:
+		return v.Z
+	default:
+		return v.W
+	}
+}
+
+func setEXRComponent(v *math.Vec4f, component int, f float32) {
+	switch component {
+	case 0:
+		v.X = f
+	case 1:
+		v.Y = f
+	case 2:
+		v.Z = f
+	default:
+		v.W = f
+	}
+}
+
+// exrLinesPerBlock returns the number of scanlines in a chunk or 0 if the compression is not supported.
+func exrLinesPerBlock(compression EXRCompression) int {
+	switch compression {
+	case EXRNoCompression, EXRZIPSCompression:
+		return 1
+	case EXRZIPCompression:
+		return 16
+	default:
+		return 0
+	}
+}
+
+// exrCompressZIP separates the odd and even bytes, encodes the differences of consecutive bytes
+// and deflates the result. Both steps improve the compression of float values.
+func exrCompressZIP(data []byte) ([]byte, error) {
+	tmp := make([]byte, len(data))
+	half := (len(data) + 1) / 2
+	for i, b := range data {
+		if i%2 == 0 {
+			tmp[i/2] = b
+		} else {
+			tmp[half+i/2] = b
+		}
+	}
+
+	prev := tmp[0]
+	for i := 1; i < len(tmp); i++ {
+		cur := tmp[i]
+		tmp[i] = cur - prev + 128
+		prev = cur
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(tmp); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// exrDecompressZIP is the inverse of exrCompressZIP and expects the size of the uncompressed data.
+func exrDecompressZIP(data []byte, size int) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := make([]byte, size)
+	if _, err := io.ReadFull(zr, tmp); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	for i := 1; i < len(tmp); i++ {
+		tmp[i] = tmp[i-1] + tmp[i] - 128
+	}
+
+	res := make([]byte, size)
+	half := (size + 1) / 2
+	for i := range res {
+		if i%2 == 0 {
+			res[i] = tmp[i/2]
+		} else {
+			res[i] = tmp[half+i/2]
+		}
+	}
+
+	return res, nil
+}
+
+// exrBuffer writes the little endian values of the format.
+type exrBuffer struct {
+	bytes.Buffer
+}
+
+func (b *exrBuffer) uint16(v uint16) {
+	var tmp [2]byte
+	binary.LittleEndian.PutUint16(tmp[:], v)
+	b.Write(tmp[:])
+}
+
+func (b *exrBuffer) uint32(v uint32) {
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], v)
+	b.Write(tmp[:])
+}
+
+func (b *exrBuffer) uint64(v uint64) {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	b.Write(tmp[:])
+}
+
+// str writes a null terminated string.
+func (b *exrBuffer) str(s string) {
+	b.WriteString(s)
+	b.WriteByte(0)
+}
+
+func (b *exrBuffer) attribute(name, typ string, value []byte) {
+	b.str(name)
+	b.str(typ)
+	b.uint32(uint32(len(value)))
+	b.Write(value)
+}
+
+func exrFloat(f float32) []byte {
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], stdmath.Float32bits(f))
+	return tmp[:]
+}
+
+// ImportEXR reads a single part scanline OpenEXR image, which is uncompressed or ZIP compressed. The channels
+// R, G, B and A become the returned canvas and the channels with a layer prefix, like "normal.R", become
+// the layers. Missing color channels are 0 and a missing alpha is 1. Other channels are ignored. The top
+// left corner of the data window becomes 0, 0 of the canvas. The canvas and all layers together must not
+// have more than MaxPixels pixels.
+func ImportEXR(r io.Reader) (Canvas, []EXRLayer, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Canvas{}, nil, err
+	}
+
+	d := &exrDecoder{data: data}
+	c, layers, err := d.decode()
+	if err != nil {
+		return Canvas{}, nil, fmt.Errorf("canvas: invalid exr: %w", err)
+	}
+
+	return c, layers, nil
+}
+
+type exrDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *exrDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *exrDecoder) uint32() (uint32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (d *exrDecoder) str() (string, error) {
+	end := bytes.IndexByte(d.data[d.pos:], 0)
+	if end < 0 {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	s := string(d.data[d.pos : d.pos+end])
+	d.pos += end + 1
+	return s, nil
+}
+
+func (d *exrDecoder) decode() (Canvas, []EXRLayer, error) {
+	magic, err := d.uint32()
+	if err != nil {
+		return Canvas{}, nil, err
+	}
+
+	if magic != exrMagic {
+		return Canvas{}, nil, errors.New("missing magic number")
+	}
+
+	version, err := d.uint32()
+	if err != nil {
+		return Canvas{}, nil, err
+	}
+
+	if version&0xff != exrVersion || version&exrUnsupportedMask != 0 {
+		return Canvas{}, nil, fmt.Errorf("unsupported version %#x", version)
+	}
+
+	var channels []exrChannel
+	var window [4]int32
+	var compression EXRCompression
+	hasChannels, hasWindow := false, false
+	for {
+		name, err := d.str()
+		if err != nil {
+			return Canvas{}, nil, err
+		}
+
+		if name == "" {
+			break
+		}
+
+		if _, err := d.str(); err != nil {
+			return Canvas{}, nil, err
+		}
+
+		size, err := d.uint32()
+		if err != nil {
+			return Canvas{}, nil, err
+		}
+
+		value, err := d.read(int(size))
+		if err != nil {
+			return Canvas{}, nil, err
+		}
+
+		switch name {
+		case "channels":
+			channels, err = parseEXRChannels(value)
+			if err != nil {
+				return Canvas{}, nil, err
+			}
+
+			hasChannels = true
+		case "compression":
+			if len(value) != 1 {
+				return Canvas{}, nil, errors.New("invalid compression attribute")
+			}
+
+			compression = EXRCompression(value[0])
+		case "dataWindow":
+			if len(value) != 16 {
+				return Canvas{}, nil, errors.New("invalid data window attribute")
+			}
+
+			for i := range window {
+				window[i] = int32(binary.LittleEndian.Uint32(value[i*4:]))
+			}
+
+			hasWindow = true
+		}
+	}
+
+	if !hasChannels || !hasWindow {
+		return Canvas{}, nil, errors.New("missing channels or data window")
+	}
+
+	linesPerBlock := exrLinesPerBlock(compression)
+	if linesPerBlock == 0 {
+		return Canvas{}, nil, fmt.Errorf("unsupported compression %d", compression)
+	}
+
+	width := int(window[2]) - int(window[0]) + 1
+	height := int(window[3]) - int(window[1]) + 1
+	if err := checkSize(width, height); err != nil {
+		return Canvas{}, nil, err
+	}
+
+	// the offset table must be present, before it is allocated
+	blocks := (height + linesPerBlock - 1) / linesPerBlock
+	if blocks*8 > len(d.data)-d.pos {
+		return Canvas{}, nil, io.ErrUnexpectedEOF
+	}
+
+	// the canvases are allocated before the data is read, so each channel knows its destination
+	c, layers, err := newEXRCanvases(channels, width, height)
+	if err != nil {
+		return Canvas{}, nil, err
+	}
+
+	pixelSize := 0
+	for i := range channels {
+		pixelSize += channels[i].size()
+	}
+
+	offsets := make([]uint64, blocks)
+	for i := range offsets {
+		b, err := d.read(8)
+		if err != nil {
+			return Canvas{}, nil, err
+		}
+
+		offsets[i] = binary.LittleEndian.Uint64(b)
+	}
+
+	for _, offset := range offsets {
+		if offset > uint64(len(d.data)) {
+			return Canvas{}, nil, errors.New("chunk offset out of range")
+		}
+
+		d.pos = int(offset)
+		if err := d.readChunk(c, channels, compression, linesPerBlock, pixelSize, int(window[1])); err != nil {
+			return Canvas{}, nil, err
+		}
+	}
+
+	return *c, layers, nil
+}
+
+// readChunk reads a block of scanlines, whose first line is relative to the data window origin y0.
+func (d *exrDecoder) readChunk(c *Canvas, channels []exrChannel, compression EXRCompression, linesPerBlock, pixelSize, y0 int) error {
+	y, err := d.uint32()
+	if err != nil {
+		return err
+	}
+
+	size, err := d.uint32()
+	if err != nil {
+		return err
+	}
+
+	data, err := d.read(int(size))
+	if err != nil {
+		return err
+	}
+
+	first := int(int32(y)) - y0
+	if first < 0 || first >= c.Height || first%linesPerBlock != 0 {
+		return fmt.Errorf("invalid chunk line %d", int32(y))
+	}
+
+	lines := linesPerBlock
+	if first+lines > c.Height {
+		lines = c.Height - first
+	}
+
+	// the data is stored raw, if compression did not pay off
+	expected := lines * c.Width * pixelSize
+	if compression != EXRNoCompression && len(data) != expected {
+		// a chunk, which cannot inflate to the expected size, must not allocate it
+		if expected/exrMaxZIPRatio > len(data) {
+			return fmt.Errorf("chunk of %d bytes cannot contain %d bytes", len(data), expected)
+		}
+
+		data, err = exrDecompressZIP(data, expected)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(data) != expected {
+		return fmt.Errorf("chunk has %d bytes, want %d", len(data), expected)
+	}
+
+	pos := 0
+	for y := first; y < first+lines; y++ {
+		for _, ch := range channels {
+			for x := 0; x < c.Width; x++ {
+				var v float32
+				switch ch.pixelType {
+				case EXRHalf:
+					v = halfToFloat32(binary.LittleEndian.Uint16(data[pos:]))
+				case EXRFloat:
+					v = stdmath.Float32frombits(binary.LittleEndian.Uint32(data[pos:]))
+				default:
+					v = float32(binary.LittleEndian.Uint32(data[pos:]))
+				}
+
+				pos += ch.size()
+				if ch.canvas != nil {
+					setEXRComponent(ch.canvas.Read(x, y), ch.component, v)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseEXRChannels reads the channel list in the order of the file, which is the order of the values.
+func parseEXRChannels(value []byte) ([]exrChannel, error) {
+	d := &exrDecoder{data: value}
+	var channels []exrChannel
+	for {
+		name, err := d.str()
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+			return channels, nil
+		}
+
+		b, err := d.read(16)
+		if err != nil {
+			return nil, err
+		}
+
+		pixelType := EXRPixelType(binary.LittleEndian.Uint32(b))
+		if pixelType != exrUint && pixelType != EXRHalf && pixelType != EXRFloat {
+			return nil, fmt.Errorf("unsupported pixel type %d of channel %q", pixelType, name)
+		}
+
+		if binary.LittleEndian.Uint32(b[8:]) != 1 || binary.LittleEndian.Uint32(b[12:]) != 1 {
+			return nil, fmt.Errorf("unsupported sampling of channel %q", name)
+		}
+
+		channels = append(channels, exrChannel{name: name, pixelType: pixelType, component: -1})
+	}
+}
+
+// newEXRCanvases allocates the canvas and the layers and connects the channels to them. The layers are
+// returned in the order of the channels. Channels, which are not a color or alpha channel, keep a nil canvas.
+// All canvases together must not exceed MaxPixels, because each layer is allocated in full, before the
+// data has been read.
+func newEXRCanvases(channels []exrChannel, width, height int) (*Canvas, []EXRLayer, error) {
+	var layers []EXRLayer
+	layerIndex := map[string]int{}
+	channelLayers := make([]int, len(channels)) // the index of the layer or -1 for the canvas
+	for i := range channels {
+		ch := &channels[i]
+		prefix, suffix := "", ch.name
+		if dot := strings.LastIndexByte(ch.name, '.'); dot >= 0 {
+			prefix, suffix = ch.name[:dot], ch.name[dot+1:]
+		}
+
+		component := strings.Index("RGBA", suffix)
+		if len(suffix) != 1 || component < 0 {
+			continue
+		}
+
+		ch.component = component
+		channelLayers[i] = -1
+		if prefix == "" {
+			continue
+		}
+
+		j, ok := layerIndex[prefix]
+		if !ok {
+			j = len(layers)
+			layerIndex[prefix] = j
+			layers = append(layers, EXRLayer{Name: prefix})
+		}
+
+		channelLayers[i] = j
+		layers[j].Channels += suffix
+	}
+
+	if 1+len(layers) > MaxPixels/(width*height) {
+		return nil, nil, fmt.Errorf("%d layers of %dx%d pixels exceed the limit", len(layers), width, height)
+	}
+
+	c := NewCanvas(width, height)
+	c.Clear(math.NewRGBA(0, 0, 0, 1))
+	for i := range layers {
+		lc := NewCanvas(width, height)
+		lc.Clear(math.NewRGBA(0, 0, 0, 1))
+		layers[i].Canvas = &lc
+	}
+
+	for i := range channels {
+		switch {
+		case channels[i].component < 0:
+			// not a color channel
+		case channelLayers[i] < 0:
+			channels[i].canvas = &c
+		default:
+			channels[i].canvas = layers[channelLayers[i]].Canvas
+		}
+	}
+
+	// the channels are sorted by name, so the letters are in the order ABGR
+	for i := range layers {
+		var letters strings.Builder
+		for _, l := range "RGBA" {
+			if strings.ContainsRune(layers[i].Channels, l) {
+				letters.WriteRune(l)
+			}
+		}
+
+		layers[i].Channels = letters.String()
+	}
+
+	return &c, layers, nil
+}
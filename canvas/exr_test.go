@@ -0,0 +1,351 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// exrTestCanvas returns a canvas with distinct values, which are exact in half precision.
+func exrTestCanvas(width, height int, offset float32) Canvas {
+	c := NewCanvas(width, height)
+	for i := range c.Buffer {
+		f := float32(i) + offset
+		c.Buffer[i] = math.NewRGBA(f/4, -f/8, f*16, 1-f/1024)
+	}
+
+	return c
+}
+
+func TestCanvas_ExportEXR_Header(t *testing.T) {
+	c := NewCanvas(1, 1)
+	c.Clear(math.NewRGBA(0, 0, 0, 1))
+	tmp := &bytes.Buffer{}
+	if err := c.ExportEXR(tmp, EXROptions{PixelType: EXRHalf}); err != nil {
+		t.Fatal(err)
+	}
+
+	chlist := ""
+	for _, name := range []string{"A", "B", "G", "R"} {
+		chlist += name + "\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x01\x00\x00\x00"
+	}
+
+	chlist += "\x00"
+	want := "\x76\x2f\x31\x01\x02\x00\x00\x00" +
+		"channels\x00chlist\x00\x49\x00\x00\x00" + chlist +
+		"compression\x00compression\x00\x01\x00\x00\x00\x00" +
+		"dataWindow\x00box2i\x00\x10\x00\x00\x00" + strings.Repeat("\x00", 16) +
+		"displayWindow\x00box2i\x00\x10\x00\x00\x00" + strings.Repeat("\x00", 16) +
+		"lineOrder\x00lineOrder\x00\x01\x00\x00\x00\x00" +
+		"pixelAspectRatio\x00float\x00\x04\x00\x00\x00\x00\x00\x80\x3f" +
+		"screenWindowCenter\x00v2f\x00\x08\x00\x00\x00" + strings.Repeat("\x00", 8) +
+		"screenWindowWidth\x00float\x00\x04\x00\x00\x00\x00\x00\x80\x3f" +
+		"\x00"
+	offset := len(want) + 8
+	want += string([]byte{byte(offset), byte(offset >> 8), 0, 0, 0, 0, 0, 0})
+	// a black pixel with an alpha of 1 in the channel order A, B, G and R
+	want += "\x00\x00\x00\x00\x08\x00\x00\x00\x00\x3c\x00\x00\x00\x00\x00\x00"
+
+	if got := tmp.String(); got != want {
+		t.Errorf("ExportEXR() = %q, want %q", got, want)
+	}
+}
+
+func TestEXR_RoundTrip(t *testing.T) {
+	tests := []struct {
+		pixelType   EXRPixelType
+		compression EXRCompression
+		width       int
+		height      int
+	}{
+		{EXRHalf, EXRNoCompression, 7, 5},
+		{EXRFloat, EXRNoCompression, 7, 5},
+		{EXRHalf, EXRZIPSCompression, 13, 9},
+		{EXRFloat, EXRZIPSCompression, 13, 9},
+		{EXRHalf, EXRZIPCompression, 31, 37},
+		{EXRFloat, EXRZIPCompression, 31, 37},
+		{EXRHalf, EXRZIPCompression, 1, 1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := exrTestCanvas(tt.width, tt.height, 0)
+			tmp := &bytes.Buffer{}
+			if err := c.ExportEXR(tmp, EXROptions{PixelType: tt.pixelType, Compression: tt.compression}); err != nil {
+				t.Fatal(err)
+			}
+
+			res, layers, err := ImportEXR(tmp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(layers) != 0 {
+				t.Errorf("len(layers) = %d, want 0", len(layers))
+			}
+
+			if res.Width != c.Width || res.Height != c.Height {
+				t.Fatalf("size = %dx%d, want %dx%d", res.Width, res.Height, c.Width, c.Height)
+			}
+
+			for j := range c.Buffer {
+				want := c.Buffer[j]
+				if tt.pixelType == EXRHalf {
+					want = math.NewRGBA(
+						halfToFloat32(float32ToHalf(want.X)),
+						halfToFloat32(float32ToHalf(want.Y)),
+						halfToFloat32(float32ToHalf(want.Z)),
+						halfToFloat32(float32ToHalf(want.W)),
+					)
+				}
+
+				if res.Buffer[j] != want {
+					t.Errorf("Buffer[%d] = %v, want %v", j, res.Buffer[j], want)
+				}
+			}
+		})
+	}
+}
+
+func TestEXR_Layers(t *testing.T) {
+	c := exrTestCanvas(5, 3, 0)
+	normals := exrTestCanvas(5, 3, 100)
+	depth := exrTestCanvas(5, 3, 200)
+	opts := EXROptions{
+		PixelType:   EXRFloat,
+		Compression: EXRZIPCompression,
+		Layers: []EXRLayer{
+			{Name: "normal", Canvas: &normals, Channels: "RGB"},
+			{Name: "depth", Canvas: &depth, Channels: "R"},
+		},
+	}
+
+	tmp := &bytes.Buffer{}
+	if err := c.ExportEXR(tmp, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	res, layers, err := ImportEXR(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range c.Buffer {
+		if res.Buffer[i] != c.Buffer[i] {
+			t.Errorf("Buffer[%d] = %v, want %v", i, res.Buffer[i], c.Buffer[i])
+		}
+	}
+
+	// the layers are sorted by name
+	if len(layers) != 2 || layers[0].Name != "depth" || layers[1].Name != "normal" {
+		t.Fatalf("layers = %v, want depth and normal", layers)
+	}
+
+	if layers[0].Channels != "R" || layers[1].Channels != "RGB" {
+		t.Errorf("channels = %s and %s, want R and RGB", layers[0].Channels, layers[1].Channels)
+	}
+
+	for i := range c.Buffer {
+		n := normals.Buffer[i]
+		n.W = 1
+		if got := layers[1].Canvas.Buffer[i]; got != n {
+			t.Errorf("normal.Buffer[%d] = %v, want %v", i, got, n)
+		}
+
+		d := math.NewRGBA(depth.Buffer[i].X, 0, 0, 1)
+		if got := layers[0].Canvas.Buffer[i]; got != d {
+			t.Errorf("depth.Buffer[%d] = %v, want %v", i, got, d)
+		}
+	}
+}
+
+func TestCanvas_ExportEXR_Invalid(t *testing.T) {
+	small := NewCanvas(1, 1)
+	tests := []EXROptions{
+		{},
+		{PixelType: EXRHalf, Compression: 1},
+		{PixelType: EXRHalf, Layers: []EXRLayer{{Name: "", Canvas: &small}}},
+		{PixelType: EXRHalf, Layers: []EXRLayer{{Name: "small", Canvas: &small}}},
+		{PixelType: EXRHalf, Layers: []EXRLayer{{Name: "nil"}}},
+		{PixelType: EXRHalf, Layers: []EXRLayer{{Name: "xyz", Canvas: nil, Channels: "XYZ"}}},
+	}
+	c := NewCanvas(2, 2)
+	tests = append(tests, EXROptions{PixelType: EXRHalf, Layers: []EXRLayer{
+		{Name: "a", Canvas: &c, Channels: "X"},
+	}}, EXROptions{PixelType: EXRHalf, Layers: []EXRLayer{
+		{Name: "a", Canvas: &c}, {Name: "a", Canvas: &c},
+	}})
+	for i, opts := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if err := c.ExportEXR(&bytes.Buffer{}, opts); err == nil {
+				t.Errorf("ExportEXR() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestImportEXRInvalid(t *testing.T) {
+	c := exrTestCanvas(3, 20, 0)
+	tmp := &bytes.Buffer{}
+	if err := c.ExportEXR(tmp, EXROptions{PixelType: EXRHalf, Compression: EXRZIPCompression}); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := tmp.String()
+	tiled := valid[:4] + "\x02\x02" + valid[6:]
+
+	// replaces the data window of the valid file
+	window := func(x0, y0, x1, y1 int32) string {
+		b := &exrBuffer{}
+		for _, v := range []int32{x0, y0, x1, y1} {
+			b.uint32(uint32(v))
+		}
+
+		i := strings.Index(valid, "dataWindow\x00box2i\x00") + 21
+		return valid[:i] + b.String() + valid[i+16:]
+	}
+
+	// 2000 float channels of 2^20 pixels would inflate to 8 GB
+	names := make([]string, 2000)
+	for i := range names {
+		names[i] = fmt.Sprintf("c%04d", i)
+	}
+
+	chunk, err := exrCompressZIP(make([]byte, 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inflated := string(craftEXR(names, EXRFloat, EXRZIPCompression, 1<<20, 1, chunk))
+
+	// 200 layers of 1024x1024 pixels would require 3 GiB
+	for i := range names[:200] {
+		names[i] = fmt.Sprintf("l%d.R", i)
+	}
+
+	layers := string(craftEXR(names[:200], EXRHalf, EXRNoCompression, 1024, 1024, nil))
+
+	tests := []string{
+		"",
+		"\x76\x2f\x31\x01",
+		"\x00\x00\x00\x00\x02\x00\x00\x00\x00",
+		"\x76\x2f\x31\x01\x02\x00\x00\x00\x00",
+		tiled,
+		valid[:len(valid)-1],
+		valid[:len(valid)/2],
+		window(-1<<31, -1<<31, 1<<31-1, 1<<31-1),
+		window(0, 0, 1<<14, 1<<13),
+		window(0, 0, 0, 1<<20), // the offset table is missing
+		inflated,
+		layers,
+	}
+	for i, exr := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, _, err := ImportEXR(strings.NewReader(exr)); err == nil {
+				t.Errorf("ImportEXR() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestImportEXR_MaxPixels(t *testing.T) {
+	defer func(old int) { MaxPixels = old }(MaxPixels)
+	c := exrTestCanvas(2, 2, 0)
+	opts := EXROptions{PixelType: EXRHalf, Layers: []EXRLayer{
+		{Name: "a", Canvas: &c}, {Name: "b", Canvas: &c}, {Name: "c", Canvas: &c},
+	}}
+	tmp := &bytes.Buffer{}
+	if err := c.ExportEXR(tmp, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// the canvas and the 3 layers have 16 pixels
+	MaxPixels = 16
+	if _, _, err := ImportEXR(bytes.NewReader(tmp.Bytes())); err != nil {
+		t.Errorf("ImportEXR() error = %v, want nil", err)
+	}
+
+	MaxPixels = 15
+	if _, _, err := ImportEXR(bytes.NewReader(tmp.Bytes())); err == nil {
+		t.Errorf("ImportEXR() error = nil, want an error")
+	}
+}
+
+// craftEXR writes a file with a single chunk, which is not validated.
+func craftEXR(names []string, pixelType EXRPixelType, compression EXRCompression, width, height int32, chunk []byte) []byte {
+	chlist := &exrBuffer{}
+	for _, name := range names {
+		chlist.str(name)
+		chlist.uint32(uint32(pixelType))
+		chlist.Write([]byte{0, 0, 0, 0})
+		chlist.uint32(1)
+		chlist.uint32(1)
+	}
+
+	chlist.WriteByte(0)
+	window := &exrBuffer{}
+	for _, v := range []int32{0, 0, width - 1, height - 1} {
+		window.uint32(uint32(v))
+	}
+
+	b := &exrBuffer{}
+	b.uint32(exrMagic)
+	b.uint32(exrVersion)
+	b.attribute("channels", "chlist", chlist.Bytes())
+	b.attribute("compression", "compression", []byte{byte(compression)})
+	b.attribute("dataWindow", "box2i", window.Bytes())
+	b.WriteByte(0)
+
+	blocks := (int(height) + exrLinesPerBlock(compression) - 1) / exrLinesPerBlock(compression)
+	offset := b.Len() + 8*blocks
+	for i := 0; i < blocks; i++ {
+		b.uint64(uint64(offset))
+	}
+
+	b.uint32(0)
+	b.uint32(uint32(len(chunk)))
+	b.Write(chunk)
+	return b.Bytes()
+}
+
+func TestEXRZIP_RoundTrip(t *testing.T) {
+	tests := [][]byte{
+		{0},
+		{1, 2, 3},
+		{255, 0, 128, 7, 7, 7, 7, 200},
+		bytes.Repeat([]byte{0, 60, 0, 188}, 100),
+	}
+	for i, data := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			compressed, err := exrCompressZIP(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := exrDecompressZIP(compressed, len(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, data) {
+				t.Errorf("exrDecompressZIP() = %v, want %v", got, data)
+			}
+		})
+	}
+}
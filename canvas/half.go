@@ -0,0 +1,93 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import "math"
+
+// float32ToHalf converts to an IEEE 754 half precision float, rounding to the nearest even value. Values
+// which are too large become infinite and values which are too small become subnormal or zero.
+func float32ToHalf(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xff
+	mant := b & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			return sign | 0x7e00 // a quiet NaN
+		}
+
+		return sign | 0x7c00
+	}
+
+	// the exponent rebased from a bias of 127 to 15
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return sign | 0x7c00
+	}
+
+	if e <= 0 {
+		if e < -10 {
+			return sign
+		}
+
+		// subnormal, so the implicit leading bit becomes part of the mantissa
+		full := mant | 0x800000
+		shift := uint(14 - e)
+		m := full >> shift
+		rem := full & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rem > halfway || (rem == halfway && m&1 == 1) {
+			m++ // may overflow into the smallest normal value, which is still correct
+		}
+
+		return sign | uint16(m)
+	}
+
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++ // may overflow into the exponent or to infinity, which is still correct
+	}
+
+	return sign | uint16(h)
+}
+
+// halfToFloat32 converts an IEEE 754 half precision float, which is always exact.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+
+		// normalize the subnormal value
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+
+		return math.Float32frombits(sign | e<<23 | (mant&0x3ff)<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestFloat32ToHalf(t *testing.T) {
+	tests := []struct {
+		f    float32
+		half uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff}, // the largest half
+		{65520, 0x7c00}, // rounds to infinity
+		{1e6, 0x7c00},   // overflow
+		{float32(math.Inf(-1)), 0xfc00},
+		{6.103515625e-05, 0x0400},         // the smallest normal half
+		{5.960464477539063e-08, 0x0001},   // the smallest subnormal half
+		{2.98023223876953125e-08, 0x0000}, // halfway to the smallest subnormal rounds to even
+		{4.470348358154297e-08, 0x0001},
+		{1e-10, 0x0000},         // underflow
+		{1.0009765625, 0x3c01},  // exact
+		{1.00048828125, 0x3c00}, // halfway rounds to even
+		{1.00146484375, 0x3c02}, // halfway rounds to even
+		{0.333333, 0x3555},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := float32ToHalf(tt.f); got != tt.half {
+				t.Errorf("float32ToHalf(%v) = %#04x, want %#04x", tt.f, got, tt.half)
+			}
+		})
+	}
+
+	if got := float32ToHalf(float32(math.NaN())); got&0x7c00 != 0x7c00 || got&0x3ff == 0 {
+		t.Errorf("float32ToHalf(NaN) = %#04x, want NaN", got)
+	}
+}
+
+func TestHalfToFloat32(t *testing.T) {
+	// every finite half survives the round trip
+	for h := 0; h <= 0xffff; h++ {
+		if h&0x7c00 == 0x7c00 && h&0x3ff != 0 {
+			if f := halfToFloat32(uint16(h)); f == f {
+				t.Fatalf("halfToFloat32(%#04x) = %v, want NaN", h, f)
+			}
+
+			continue
+		}
+
+		f := halfToFloat32(uint16(h))
+		if got := float32ToHalf(f); got != uint16(h) {
+			t.Fatalf("float32ToHalf(halfToFloat32(%#04x)) = %#04x", h, got)
+		}
+	}
+
+	tests := []struct {
+		half uint16
+		f    float32
+	}{
+		{0x3c00, 1},
+		{0xc000, -2},
+		{0x7bff, 65504},
+		{0x0001, 5.960464477539063e-08},
+		{0x03ff, 6.097555160522461e-05},
+		{0x7c00, float32(math.Inf(1))},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := halfToFloat32(tt.half); got != tt.f {
+				t.Errorf("halfToFloat32(%#04x) = %v, want %v", tt.half, got, tt.f)
+			}
+		})
+	}
+}
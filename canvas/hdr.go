@@ -0,0 +1,356 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/torbenschinke/rtc/math"
+	"io"
+	stdmath "math"
+	"strconv"
+	"strings"
+)
+
+const (
+	hdrMinRun       = 4      // shorter runs are cheaper as literals
+	hdrMinRLEWidth  = 8      // the run length encoding is only defined for widths from 8
+	hdrMaxRLEWidth  = 0x7fff // up to 32767
+	hdrMaxRunLength = 127
+	hdrMaxLiterals  = 128
+)
+
+// ExportHDR writes the buffer as a Radiance picture (.hdr) in the RGBE format, which stores a shared
+// exponent for the three color channels. The scanlines are run length encoded. Unlike Export, the colors
+// are not clamped to 1, but the format cannot store negative values, which become 0. The alpha channel
+// is dropped.
+func (c *Canvas) ExportHDR(w io.Writer) error {
+	// the errors of a bufio.Writer are sticky and returned by Flush
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", c.Height, c.Width)
+
+	scanline := make([]byte, c.Width*4)
+	channel := make([]byte, c.Width)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			toRGBE(c.Read(x, y), scanline[x*4:])
+		}
+
+		if c.Width < hdrMinRLEWidth || c.Width > hdrMaxRLEWidth {
+			bw.Write(scanline)
+			continue
+		}
+
+		bw.Write([]byte{2, 2, byte(c.Width >> 8), byte(c.Width)})
+		for ch := 0; ch < 4; ch++ {
+			for x := range channel {
+				channel[x] = scanline[x*4+ch]
+			}
+
+			writeRLE(bw, channel)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeRLE encodes the bytes of a single channel as runs and literals. A run is a count
+// above 128 followed by the repeated byte, literals are a count up to 128 followed by the bytes.
+func writeRLE(w *bufio.Writer, data []byte) {
+	cur := 0
+	for cur < len(data) {
+		// find the next run, which is long enough
+		begRun := cur
+		runCount := 0
+		oldRunCount := 0
+		for runCount < hdrMinRun && begRun < len(data) {
+			begRun += runCount
+			oldRunCount = runCount
+			runCount = 1
+			for begRun+runCount < len(data) && runCount < hdrMaxRunLength && data[begRun] == data[begRun+runCount] {
+				runCount++
+			}
+		}
+
+		// a short run directly in front of the long run is still cheaper as a run
+		if oldRunCount > 1 && oldRunCount == begRun-cur {
+			w.WriteByte(byte(128 + oldRunCount))
+			w.WriteByte(data[cur])
+			cur = begRun
+		}
+
+		for cur < begRun {
+			n := begRun - cur
+			if n > hdrMaxLiterals {
+				n = hdrMaxLiterals
+			}
+
+			w.WriteByte(byte(n))
+			w.Write(data[cur : cur+n])
+			cur += n
+		}
+
+		if runCount >= hdrMinRun {
+			w.WriteByte(byte(128 + runCount))
+			w.WriteByte(data[begRun])
+			cur += runCount
+		}
+	}
+}
+
+// toRGBE converts the color into mantissas and a shared exponent, which are written into the first 4 bytes of dst.
+func toRGBE(v *math.Vec4f, dst []byte) {
+	r := stdmath.Max(float64(v.X), 0)
+	g := stdmath.Max(float64(v.Y), 0)
+	b := stdmath.Max(float64(v.Z), 0)
+	m := stdmath.Max(r, stdmath.Max(g, b))
+
+	// also catches NaN
+	if !(m >= 1e-32) {
+		dst[0], dst[1], dst[2], dst[3] = 0, 0, 0, 0
+		return
+	}
+
+	frac, exp := stdmath.Frexp(m)
+	if exp > 127 {
+		// saturate, because the exponent cannot be represented
+		dst[0], dst[1], dst[2], dst[3] = 0xff, 0xff, 0xff, 0xff
+		return
+	}
+
+	scale := frac * 256 / m
+	dst[0] = byte(r * scale)
+	dst[1] = byte(g * scale)
+	dst[2] = byte(b * scale)
+	dst[3] = byte(exp + 128)
+}
+
+// fromRGBE is the inverse of toRGBE.
+func fromRGBE(src []byte) math.Vec4f {
+	if src[3] == 0 {
+		return math.NewRGB(0, 0, 0)
+	}
+
+	f := stdmath.Ldexp(1, int(src[3])-(128+8))
+	return math.NewRGB(float32(float64(src[0])*f), float32(float64(src[1])*f), float32(float64(src[2])*f))
+}
+
+// ImportHDR reads a Radiance picture (.hdr) in the RGBE format with flat or run length encoded scanlines.
+// Only the standard orientation (-Y height +X width) is supported. Values are divided by the EXPOSURE
+// of the header, so that the result is the original radiance. The alpha is always 1. Images with more than
+// MaxPixels pixels are rejected.
+func ImportHDR(r io.Reader) (Canvas, error) {
+	c, err := importHDR(bufio.NewReader(r))
+	if err != nil {
+		return Canvas{}, fmt.Errorf("canvas: invalid hdr: %w", err)
+	}
+
+	return c, nil
+}
+
+func importHDR(r *bufio.Reader) (Canvas, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return Canvas{}, unexpectedEOF(err)
+	}
+
+	if !strings.HasPrefix(line, "#?") {
+		return Canvas{}, errors.New("missing magic number")
+	}
+
+	// the header ends with an empty line
+	exposure := 1.0
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return Canvas{}, unexpectedEOF(err)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "FORMAT="):
+			if format := strings.TrimPrefix(line, "FORMAT="); format != "32-bit_rle_rgbe" {
+				return Canvas{}, fmt.Errorf("unsupported format %q", format)
+			}
+		case strings.HasPrefix(line, "EXPOSURE="):
+			e, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(line, "EXPOSURE=")), 64)
+			if err != nil {
+				return Canvas{}, fmt.Errorf("invalid exposure: %w", err)
+			}
+
+			// multiple exposures are cumulative
+			exposure *= e
+		}
+	}
+
+	line, err = r.ReadString('\n')
+	if err != nil {
+		return Canvas{}, unexpectedEOF(err)
+	}
+
+	var width, height int
+	if _, err := fmt.Sscanf(line, "-Y %d +X %d\n", &height, &width); err != nil {
+		return Canvas{}, fmt.Errorf("unsupported resolution %q", strings.TrimSpace(line))
+	}
+
+	if err := checkSize(width, height); err != nil {
+		return Canvas{}, err
+	}
+
+	if exposure <= 0 {
+		return Canvas{}, fmt.Errorf("invalid exposure %v", exposure)
+	}
+
+	// the pixels are appended, as they are read
+	c := Canvas{Width: width, Height: height}
+	var scanline []byte
+	for y := 0; y < height; y++ {
+		scanline, err = readScanline(r, scanline, width)
+		if err != nil {
+			return Canvas{}, err
+		}
+
+		for x := 0; x < width; x++ {
+			v := fromRGBE(scanline[x*4:])
+			v.X = float32(float64(v.X) / exposure)
+			v.Y = float32(float64(v.Y) / exposure)
+			v.Z = float32(float64(v.Z) / exposure)
+			c.Buffer = append(c.Buffer, v)
+		}
+	}
+
+	return c, nil
+}
+
+// readScanline reads a run length encoded scanline or, if the scanline does not start with the
+// marker, a flat one. The buffer of the previous scanline is reused, if possible.
+func readScanline(r *bufio.Reader, scanline []byte, width int) ([]byte, error) {
+	if width < hdrMinRLEWidth || width > hdrMaxRLEWidth {
+		return readFlatScanline(r, scanline, width)
+	}
+
+	marker, err := r.Peek(4)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if marker[0] != 2 || marker[1] != 2 || marker[2]&0x80 != 0 {
+		return readFlatScanline(r, scanline, width)
+	}
+
+	if int(marker[2])<<8|int(marker[3]) != width {
+		return nil, errors.New("scanline width mismatch")
+	}
+
+	if _, err := r.Discard(4); err != nil {
+		return nil, err
+	}
+
+	// the width is small, so the whole scanline is allocated in advance
+	if cap(scanline) < width*4 {
+		scanline = make([]byte, width*4)
+	}
+
+	scanline = scanline[:width*4]
+
+	// each channel is encoded separately
+	for ch := 0; ch < 4; ch++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			if count > 128 {
+				n := int(count) - 128
+				if x+n > width {
+					return nil, errors.New("run exceeds the scanline")
+				}
+
+				b, err := r.ReadByte()
+				if err != nil {
+					return nil, unexpectedEOF(err)
+				}
+
+				for ; n > 0; n-- {
+					scanline[x*4+ch] = b
+					x++
+				}
+
+				continue
+			}
+
+			n := int(count)
+			if n == 0 || x+n > width {
+				return nil, errors.New("invalid literal count")
+			}
+
+			for ; n > 0; n-- {
+				b, err := r.ReadByte()
+				if err != nil {
+					return nil, unexpectedEOF(err)
+				}
+
+				scanline[x*4+ch] = b
+				x++
+			}
+		}
+	}
+
+	return scanline, nil
+}
+
+// readFlatScanline reads uncompressed pixels, which may contain the run length encoding of the original
+// format. A pixel of 1, 1, 1 repeats the previous pixel by its exponent, which is shifted by 8 bits for
+// each consecutive repetition. The pixels are appended to the emptied buffer, as they are read.
+func readFlatScanline(r *bufio.Reader, scanline []byte, width int) ([]byte, error) {
+	scanline = scanline[:0]
+	shift := uint(0)
+	var px [4]byte
+	for len(scanline) < width*4 {
+		if _, err := io.ReadFull(r, px[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if px[0] != 1 || px[1] != 1 || px[2] != 1 {
+			shift = 0
+			scanline = append(scanline, px[:]...)
+			continue
+		}
+
+		if len(scanline) == 0 {
+			return nil, errors.New("repetition without a previous pixel")
+		}
+
+		n := int(px[3]) << shift
+		if len(scanline)/4+n > width {
+			return nil, errors.New("repetition exceeds the scanline")
+		}
+
+		copy(px[:], scanline[len(scanline)-4:])
+		for ; n > 0; n-- {
+			scanline = append(scanline, px[:]...)
+		}
+
+		shift += 8
+	}
+
+	return scanline, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRGBE(t *testing.T) {
+	tests := []struct {
+		color math.Vec4f
+		rgbe  [4]byte
+	}{
+		{math.NewRGB(0, 0, 0), [4]byte{0, 0, 0, 0}},
+		{math.NewRGB(1, 1, 1), [4]byte{128, 128, 128, 129}},
+		{math.NewRGB(1, 0.5, 0.25), [4]byte{128, 64, 32, 129}},
+		{math.NewRGB(-1, 3, 0), [4]byte{0, 192, 0, 130}},
+		{math.NewRGB(1000, 0, 0), [4]byte{250, 0, 0, 138}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var got [4]byte
+			toRGBE(&tt.color, got[:])
+			if got != tt.rgbe {
+				t.Errorf("toRGBE(%v) = %v, want %v", tt.color, got, tt.rgbe)
+			}
+
+			// negative values cannot be represented
+			want := tt.color
+			if want.X < 0 {
+				want.X = 0
+			}
+
+			if res := fromRGBE(got[:]); res != want {
+				t.Errorf("fromRGBE(%v) = %v, want %v", got, res, want)
+			}
+		})
+	}
+}
+
+func TestWriteRLE(t *testing.T) {
+	tests := []struct {
+		data string
+		rle  string
+	}{
+		{"abc", "\x03abc"},
+		{"aaaa", "\x84a"},
+		{"aabbbbbc", "\x82a\x85b\x01c"},
+		{"abaaaa", "\x02ab\x84a"},
+		{strings.Repeat("x", 130), "\xffx\x83x"},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := &bytes.Buffer{}
+			w := bufio.NewWriter(tmp)
+			writeRLE(w, []byte(tt.data))
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := tmp.String(); got != tt.rle {
+				t.Errorf("writeRLE(%q) = %q, want %q", tt.data, got, tt.rle)
+			}
+		})
+	}
+}
+
+func TestHDR_RoundTrip(t *testing.T) {
+	tests := []struct {
+		width, height int
+	}{
+		{1, 1},
+		{7, 3},   // too narrow for rle
+		{100, 4}, // rle
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCanvas(tt.width, tt.height)
+			for y := 0; y < c.Height; y++ {
+				for x := 0; x < c.Width; x++ {
+					// long runs in the left half, noise in the right half
+					v := math.NewRGB(50, 0.5, 0.001)
+					if x > c.Width/2 {
+						v = math.NewRGB(float32(x*y)*3.7, float32(x)/10, float32(y))
+					}
+
+					c.Write(x, y, &v)
+				}
+			}
+
+			tmp := &bytes.Buffer{}
+			if err := c.ExportHDR(tmp); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := ImportHDR(tmp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Width != c.Width || res.Height != c.Height {
+				t.Fatalf("size = %dx%d, want %dx%d", res.Width, res.Height, c.Width, c.Height)
+			}
+
+			// the error is relative to the largest channel, which has 8 bit of precision
+			for j := range c.Buffer {
+				a, b := &c.Buffer[j], &res.Buffer[j]
+				tolerance := math.Max(a.X, math.Max(a.Y, a.Z)) / 128
+				if math.Abs(a.X-b.X) > tolerance || math.Abs(a.Y-b.Y) > tolerance || math.Abs(a.Z-b.Z) > tolerance {
+					t.Fatalf("Buffer[%d] = %v, want %v", j, *b, *a)
+				}
+			}
+		})
+	}
+}
+
+func TestCanvas_ExportHDRCompression(t *testing.T) {
+	c := NewCanvas(1000, 10)
+	c.Clear(math.NewRGB(0.3, 0.4, 0.5))
+	tmp := &bytes.Buffer{}
+	if err := c.ExportHDR(tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	// each channel of a scanline needs 8 runs of 127 and a single run of 984 - 8*127
+	if size := tmp.Len(); size > 1000 {
+		t.Errorf("size = %v, want at most 1000", size)
+	}
+}
+
+func TestImportHDR(t *testing.T) {
+	header := "#?RGBE\n# a comment\nFORMAT=32-bit_rle_rgbe\nEXPOSURE=2\nEXPOSURE= 0.25\n\n-Y 1 +X 4\n"
+	tests := []struct {
+		raster string
+		res    []math.Vec4f
+	}{
+		// flat
+		{
+			"\x80\x80\x80\x81\x80\x40\x20\x81\x00\x00\x00\x00\x80\x80\x80\x81",
+			[]math.Vec4f{math.NewRGB(2, 2, 2), math.NewRGB(2, 1, 0.5), math.NewRGB(0, 0, 0), math.NewRGB(2, 2, 2)},
+		},
+		// the repetition of the original format
+		{
+			"\x80\x40\x20\x81\x01\x01\x01\x03",
+			[]math.Vec4f{math.NewRGB(2, 1, 0.5), math.NewRGB(2, 1, 0.5), math.NewRGB(2, 1, 0.5), math.NewRGB(2, 1, 0.5)},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c, err := ImportHDR(strings.NewReader(header + tt.raster))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for j := range tt.res {
+				if c.Buffer[j] != tt.res[j] {
+					t.Errorf("Buffer[%d] = %v, want %v", j, c.Buffer[j], tt.res[j])
+				}
+			}
+		})
+	}
+}
+
+func TestImportHDRInvalid(t *testing.T) {
+	rle := "#?RADIANCE\n\n-Y 1 +X 8\n\x02\x02\x00\x08"
+	tests := []string{
+		"",
+		"P6\n\n-Y 1 +X 1\n\x00\x00\x00\x00",
+		"#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n\x00\x00\x00\x00",
+		"#?RADIANCE\n\n+Y 1 +X 1\n\x00\x00\x00\x00",
+		"#?RADIANCE\n\n-Y 1 +X 2\n\x00\x00\x00\x00",
+		"#?RADIANCE\n\n-Y 1 +X 1\n\x01\x01\x01\x01",
+		"#?RADIANCE\n\n-Y 1 +X 8\n\x02\x02\x00\x09",
+		rle + "\x89\x00",
+		rle + "\x00",
+		rle + "\x88\x00\x88\x00\x88\x00\x08abcdefg",
+		"#?RADIANCE\n\n-Y 4611686018427387905 +X 2\n",
+		"#?RADIANCE\n\n-Y 2 +X 4611686018427387904\n",
+	}
+	for i, hdr := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := ImportHDR(strings.NewReader(hdr)); err == nil {
+				t.Errorf("ImportHDR() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestImportHDR_Truncated(t *testing.T) {
+	// the header promises up to 2 GiB, but the file contains no pixels
+	tests := []string{
+		"#?RADIANCE\n\n-Y 8192 +X 16384\n",
+		"#?RADIANCE\n\n-Y 1 +X 134217728\n",
+		"#?RADIANCE\n\n-Y 8192 +X 1024\n\x02\x02\x04\x00",
+	}
+	for i, data := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			allocated := allocatedBytes(func() {
+				_, err = ImportHDR(strings.NewReader(data))
+			})
+
+			if err == nil {
+				t.Errorf("ImportHDR() error = nil, want an error")
+			}
+
+			if allocated > 1<<20 {
+				t.Errorf("ImportHDR() allocated %d bytes, want at most 1 MiB", allocated)
+			}
+		})
+	}
+}
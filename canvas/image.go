@@ -0,0 +1,114 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"image"
+	"image/color"
+)
+
+// Image adapts a Canvas to the image.Image and draw.Image interfaces of the standard library, so that
+// a canvas can be encoded by e.g. image/png. The canvas stores linear colors with straight alpha, but
+// the standard library expects sRGB encoded colors, so At and Set convert the color channels. Alpha
+// is always linear. Values outside of [0, 1] are clamped, so use an HDR format to keep them.
+type Image struct {
+	canvas *Canvas
+}
+
+// NewImage creates an adapter, which reads and writes the given canvas.
+func NewImage(c *Canvas) *Image {
+	return &Image{canvas: c}
+}
+
+// Canvas returns the adapted canvas.
+func (i *Image) Canvas() *Canvas {
+	return i.canvas
+}
+
+// ColorModel returns color.NRGBA64Model, because the canvas does not premultiply the alpha.
+func (i *Image) ColorModel() color.Model {
+	return color.NRGBA64Model
+}
+
+// Bounds returns the rectangle from 0, 0 to the width and height of the canvas.
+func (i *Image) Bounds() image.Rectangle {
+	return image.Rect(0, 0, i.canvas.Width, i.canvas.Height)
+}
+
+// At returns the sRGB encoded color as color.NRGBA64 or a transparent black outside of the bounds.
+func (i *Image) At(x, y int) color.Color {
+	if !(image.Point{X: x, Y: y}.In(i.Bounds())) {
+		return color.NRGBA64{}
+	}
+
+	v := *i.canvas.Read(x, y)
+	v.Saturate()
+	return color.NRGBA64{
+		R: quantize16(math.LinearToSRGB(v.X)),
+		G: quantize16(math.LinearToSRGB(v.Y)),
+		B: quantize16(math.LinearToSRGB(v.Z)),
+		A: quantize16(v.W),
+	}
+}
+
+// Set decodes the sRGB encoded color into the canvas. Points outside of the bounds are ignored.
+func (i *Image) Set(x, y int, c color.Color) {
+	if !(image.Point{X: x, Y: y}.In(i.Bounds())) {
+		return
+	}
+
+	v := fromColor(c)
+	i.canvas.Write(x, y, &v)
+}
+
+// FromImage creates a canvas with the linear colors of the image. The top left corner of the image
+// bounds becomes 0, 0 of the canvas.
+func FromImage(img image.Image) Canvas {
+	b := img.Bounds()
+	c := NewCanvas(b.Dx(), b.Dy())
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			v := fromColor(img.At(b.Min.X+x, b.Min.Y+y))
+			c.Write(x, y, &v)
+		}
+	}
+
+	return c
+}
+
+// fromColor converts the color into non premultiplied values and decodes them into linear colors.
+func fromColor(c color.Color) math.Vec4f {
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	return math.NewRGBA(
+		math.SRGBToLinear(float32(n.R)/0xffff),
+		math.SRGBToLinear(float32(n.G)/0xffff),
+		math.SRGBToLinear(float32(n.B)/0xffff),
+		float32(n.A)/0xffff,
+	)
+}
+
+// quantize16 scales the value from [0, 1] to [0, 65535]. The value is clamped again, because the transfer
+// function may overshoot 1 by a rounding error.
+func quantize16(v float32) uint16 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 0xffff
+	default:
+		return uint16(v*0xffff + 0.5)
+	}
+}
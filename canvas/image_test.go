@@ -0,0 +1,165 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"github.com/torbenschinke/rtc/math"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"testing"
+)
+
+// the adapter must be usable wherever the standard library expects an image
+var _ draw.Image = (*Image)(nil)
+
+func TestImage_At(t *testing.T) {
+	c := NewCanvas(3, 2)
+	colors := []math.Vec4f{
+		math.NewRGB(1, 0.5, 0),
+		math.NewRGBA(2, -1, 0.21404, 0.5),
+	}
+	c.Write(0, 0, &colors[0])
+	c.Write(2, 1, &colors[1])
+	img := NewImage(&c)
+
+	if img.Canvas() != &c {
+		t.Errorf("Canvas() = %p, want %p", img.Canvas(), &c)
+	}
+
+	if b := img.Bounds(); b != image.Rect(0, 0, 3, 2) {
+		t.Errorf("Bounds() = %v, want %v", b, image.Rect(0, 0, 3, 2))
+	}
+
+	tests := []struct {
+		x, y int
+		res  color.NRGBA64
+	}{
+		{0, 0, color.NRGBA64{R: 0xffff, G: 48192, B: 0, A: 0xffff}},
+		{2, 1, color.NRGBA64{R: 0xffff, G: 0, B: 32768, A: 32768}},
+		{1, 1, color.NRGBA64{A: 0}},
+		{-1, 0, color.NRGBA64{}},
+		{3, 0, color.NRGBA64{}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := img.At(tt.x, tt.y).(color.NRGBA64)
+			// allow a rounding difference of the transfer function
+			for j, pair := range [][2]uint16{{got.R, tt.res.R}, {got.G, tt.res.G}, {got.B, tt.res.B}, {got.A, tt.res.A}} {
+				if d := int(pair[0]) - int(pair[1]); d < -2 || d > 2 {
+					t.Errorf("At(%d, %d) channel %d = %v, want %v", tt.x, tt.y, j, got, tt.res)
+				}
+			}
+		})
+	}
+}
+
+func TestImage_Set(t *testing.T) {
+	c := NewCanvas(2, 2)
+	img := NewImage(&c)
+
+	img.Set(1, 0, color.NRGBA{R: 255, G: 128, B: 0, A: 255})
+	img.Set(0, 1, color.RGBA{R: 64, G: 0, B: 0, A: 128}) // premultiplied, so the straight red is 0.5
+	img.Set(5, 5, color.White)                           // ignored
+
+	tests := []struct {
+		x, y int
+		res  math.Vec4f
+	}{
+		{1, 0, math.NewRGBA(1, 0.21586, 0, 1)},
+		{0, 1, math.NewRGBA(0.21404, 0, 0, 0.50196)},
+		{0, 0, math.NewRGBA(0, 0, 0, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := c.Read(tt.x, tt.y); !equalsApprox(got, &tt.res) {
+				t.Errorf("Read(%d, %d) = %v, want %v", tt.x, tt.y, *got, tt.res)
+			}
+		})
+	}
+
+	// draw.Draw uses Set
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	white := math.NewRGB(1, 1, 1)
+	for i := range c.Buffer {
+		if !c.Buffer[i].Equals(&white) {
+			t.Errorf("Buffer[%d] = %v, want %v", i, c.Buffer[i], white)
+		}
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(10, 20, 13, 22))
+	src.Set(10, 20, color.NRGBA{R: 255, A: 255})
+	src.Set(12, 21, color.NRGBA{G: 188, B: 255, A: 51})
+
+	c := FromImage(src)
+	if c.Width != 3 || c.Height != 2 {
+		t.Fatalf("size = %dx%d, want 3x2", c.Width, c.Height)
+	}
+
+	tests := []struct {
+		x, y int
+		res  math.Vec4f
+	}{
+		{0, 0, math.NewRGBA(1, 0, 0, 1)},
+		{2, 1, math.NewRGBA(0, 0.50289, 1, 0.2)},
+		{1, 0, math.NewRGBA(0, 0, 0, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := c.Read(tt.x, tt.y); !equalsApprox(got, &tt.res) {
+				t.Errorf("Read(%d, %d) = %v, want %v", tt.x, tt.y, *got, tt.res)
+			}
+		})
+	}
+}
+
+func TestImage_PNGRoundTrip(t *testing.T) {
+	c := NewCanvas(16, 4)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			color := math.NewRGBA(float32(x)/15, float32(y)/3, 0.25, 1)
+			c.Write(x, y, &color)
+		}
+	}
+
+	tmp := &bytes.Buffer{}
+	if err := png.Encode(tmp, NewImage(&c)); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := png.Decode(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := FromImage(decoded)
+	for i := range c.Buffer {
+		if !equalsApprox(&res.Buffer[i], &c.Buffer[i]) {
+			t.Errorf("Buffer[%d] = %v, want %v", i, res.Buffer[i], c.Buffer[i])
+		}
+	}
+}
+
+// equalsApprox compares with a tolerance, which is suitable for quantized colors.
+func equalsApprox(a, b *math.Vec4f) bool {
+	const epsilon = 1e-3
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon &&
+		math.Abs(a.Z-b.Z) < epsilon && math.Abs(a.W-b.W) < epsilon
+}
@@ -0,0 +1,311 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/torbenschinke/rtc/math"
+	"io"
+	"strconv"
+)
+
+// Import reads a Netpbm image, which is a plain or binary bitmap (P1, P4), graymap (P2, P5) or
+// pixmap (P3, P6), as written by Export. The values are normalized by the maximum value into [0, 1]
+// and the alpha is always 1. Black pixels in a bitmap are set, so 1 is black and 0 is white. Only
+// the first image of a multi image file is read. Images with more than MaxPixels pixels are rejected.
+func Import(r io.Reader) (Canvas, error) {
+	d := &pnmDecoder{r: bufio.NewReader(r)}
+	c, err := d.decode()
+	if err != nil {
+		return Canvas{}, fmt.Errorf("canvas: invalid pnm: %w", err)
+	}
+
+	return c, nil
+}
+
+// MaxPixels limits the size of decoded images. The default allows e.g. 16384x8192 pixels, which require
+// 2 GiB. The decoders grow the buffer while the pixels are read, so a truncated file fails before the
+// memory of its header size is allocated, but a valid or compressed file may still use all of it. Lower
+// the limit before decoding untrusted input.
+var MaxPixels = 1 << 27
+
+// checkSize validates the size from an image header.
+func checkSize(width, height int) error {
+	if width < 1 || height < 1 || width > MaxPixels/height {
+		return fmt.Errorf("invalid size %dx%d", width, height)
+	}
+
+	return nil
+}
+
+type pnmDecoder struct {
+	r *bufio.Reader
+}
+
+func (d *pnmDecoder) decode() (Canvas, error) {
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(d.r, magic); err != nil {
+		return Canvas{}, err
+	}
+
+	if magic[0] != 'P' || magic[1] < '1' || magic[1] > '6' {
+		return Canvas{}, fmt.Errorf("unknown magic number %q", magic)
+	}
+
+	format := magic[1]
+	width, err := d.readHeaderInt()
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	height, err := d.readHeaderInt()
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	if err := checkSize(width, height); err != nil {
+		return Canvas{}, err
+	}
+
+	maxVal := 1
+	if format != '1' && format != '4' {
+		maxVal, err = d.readHeaderInt()
+		if err != nil {
+			return Canvas{}, err
+		}
+
+		if maxVal < 1 || maxVal > 65535 {
+			return Canvas{}, fmt.Errorf("maxval %d out of range [1, 65535]", maxVal)
+		}
+	}
+
+	if format >= '4' {
+		// the raster of the binary formats follows after exactly one whitespace
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return Canvas{}, err
+		}
+
+		if !isSpace(b) {
+			return Canvas{}, errors.New("missing whitespace after the header")
+		}
+	}
+
+	// the pixels are appended, as they are read
+	c := Canvas{Width: width, Height: height}
+	switch format {
+	case '1':
+		err = d.readPlainBitmap(&c)
+	case '4':
+		err = d.readBinaryBitmap(&c)
+	default:
+		channels := 1
+		if format == '3' || format == '6' {
+			channels = 3
+		}
+
+		next := d.readPlainValue
+		if format >= '4' {
+			next = d.readBinaryValue
+		}
+
+		err = d.readSamples(&c, channels, maxVal, next)
+	}
+
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	return c, nil
+}
+
+// readSamples reads gray or rgb values, which are provided by next.
+func (d *pnmDecoder) readSamples(c *Canvas, channels, maxVal int, next func(maxVal int) (int, error)) error {
+	scale := 1 / float32(maxVal)
+	var v [3]float32
+	for i := 0; i < c.Width*c.Height; i++ {
+		for ch := 0; ch < channels; ch++ {
+			n, err := next(maxVal)
+			if err != nil {
+				return err
+			}
+
+			if n > maxVal {
+				return fmt.Errorf("value %d exceeds maxval %d", n, maxVal)
+			}
+
+			v[ch] = float32(n) * scale
+		}
+
+		if channels == 1 {
+			c.Buffer = append(c.Buffer, math.NewRGB(v[0], v[0], v[0]))
+		} else {
+			c.Buffer = append(c.Buffer, math.NewRGB(v[0], v[1], v[2]))
+		}
+	}
+
+	return nil
+}
+
+func (d *pnmDecoder) readPlainValue(int) (int, error) {
+	return d.readHeaderInt()
+}
+
+// readBinaryValue reads a single byte or, if the maxval is larger than 255, two bytes in big endian order.
+func (d *pnmDecoder) readBinaryValue(maxVal int) (int, error) {
+	hi, err := d.r.ReadByte()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+
+	if maxVal < 256 {
+		return int(hi), nil
+	}
+
+	lo, err := d.r.ReadByte()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+
+	return int(hi)<<8 | int(lo), nil
+}
+
+// readPlainBitmap reads the digits 0 and 1, which do not need to be separated by whitespace.
+func (d *pnmDecoder) readPlainBitmap(c *Canvas) error {
+	for i := 0; i < c.Width*c.Height; i++ {
+		if err := d.skipSpaceAndComments(); err != nil {
+			return unexpectedEOF(err)
+		}
+
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+
+		switch b {
+		case '0':
+			c.Buffer = append(c.Buffer, math.NewRGB(1, 1, 1))
+		case '1':
+			c.Buffer = append(c.Buffer, math.NewRGB(0, 0, 0))
+		default:
+			return fmt.Errorf("invalid bit %q", b)
+		}
+	}
+
+	return nil
+}
+
+// readBinaryBitmap reads 8 pixels per byte, starting with the most significant bit. Each row starts with
+// a new byte.
+func (d *pnmDecoder) readBinaryBitmap(c *Canvas) error {
+	row := make([]byte, (c.Width+7)/8)
+	for y := 0; y < c.Height; y++ {
+		if _, err := io.ReadFull(d.r, row); err != nil {
+			return unexpectedEOF(err)
+		}
+
+		for x := 0; x < c.Width; x++ {
+			if row[x/8]&(0x80>>(x%8)) != 0 {
+				c.Buffer = append(c.Buffer, math.NewRGB(0, 0, 0))
+			} else {
+				c.Buffer = append(c.Buffer, math.NewRGB(1, 1, 1))
+			}
+		}
+	}
+
+	return nil
+}
+
+// readHeaderInt skips whitespace and comments and reads a decimal number.
+func (d *pnmDecoder) readHeaderInt() (int, error) {
+	if err := d.skipSpaceAndComments(); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+
+	var digits []byte
+	for {
+		b, err := d.r.ReadByte()
+		if err == io.EOF && len(digits) > 0 {
+			break
+		}
+
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+
+		if b < '0' || b > '9' {
+			if !isSpace(b) && b != '#' {
+				return 0, fmt.Errorf("unexpected character %q", b)
+			}
+
+			// a comment may directly follow a number and the whitespace belongs to the next token
+			if err := d.r.UnreadByte(); err != nil {
+				return 0, err
+			}
+
+			break
+		}
+
+		digits = append(digits, b)
+	}
+
+	if len(digits) == 0 {
+		return 0, errors.New("missing number")
+	}
+
+	n, err := strconv.Atoi(string(digits))
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// skipSpaceAndComments reads until the next byte, which is neither whitespace nor part of a comment.
+// A comment starts with # and ends at the end of the line.
+func (d *pnmDecoder) skipSpaceAndComments() error {
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case isSpace(b):
+			continue
+		case b == '#':
+			if _, err := d.r.ReadBytes('\n'); err != nil {
+				return err
+			}
+		default:
+			return d.r.UnreadByte()
+		}
+	}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\v' || b == '\f' || b == '\r'
+}
+
+// unexpectedEOF converts io.EOF, because the image is truncated.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,202 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"github.com/torbenschinke/rtc/math"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	black := math.NewRGB(0, 0, 0)
+	white := math.NewRGB(1, 1, 1)
+	tests := []struct {
+		pnm           string
+		width, height int
+		res           []math.Vec4f
+	}{
+		{"P1\n# a comment\n3 2\n1 0 1\n0 1 0\n", 3, 2, []math.Vec4f{black, white, black, white, black, white}},
+		{"P1 3 2 101#comment\n010", 3, 2, []math.Vec4f{black, white, black, white, black, white}},
+		{
+			"P4\n10 2\n\xa0\xc0\x00\x40", 10, 2,
+			[]math.Vec4f{
+				black, white, black, white, white, white, white, white, black, black,
+				white, white, white, white, white, white, white, white, white, black,
+			},
+		},
+		{
+			"P2\n2 2\n15\n0 15\n5 10", 2, 2,
+			[]math.Vec4f{black, white, math.NewRGB(1.0/3, 1.0/3, 1.0/3), math.NewRGB(2.0/3, 2.0/3, 2.0/3)},
+		},
+		{"P5 2 1 255\n\x00\xff", 2, 1, []math.Vec4f{black, white}},
+		{"P5 2 1 65535\n\x00\x00\xff\xff", 2, 1, []math.Vec4f{black, white}},
+		{
+			"P3\n# created by hand\n2 1 # size\n255\n255 0 0\n0 51\n255", 2, 1,
+			[]math.Vec4f{math.NewRGB(1, 0, 0), math.NewRGB(0, 0.2, 1)},
+		},
+		{"P6\n2 1\n255\n\xff\x00\x00\x00\x33\xff", 2, 1, []math.Vec4f{math.NewRGB(1, 0, 0), math.NewRGB(0, 0.2, 1)}},
+		{
+			"P6\n1 1\n1000\n\x03\xe8\x01\xf4\x00\x00", 1, 1,
+			[]math.Vec4f{math.NewRGB(1, 0.5, 0)},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c, err := Import(strings.NewReader(tt.pnm))
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			if c.Width != tt.width || c.Height != tt.height {
+				t.Fatalf("size = %dx%d, want %dx%d", c.Width, c.Height, tt.width, tt.height)
+			}
+
+			for j := range tt.res {
+				if !c.Buffer[j].Equals(&tt.res[j]) {
+					t.Errorf("Buffer[%d] = %v, want %v", j, c.Buffer[j], tt.res[j])
+				}
+			}
+		})
+	}
+}
+
+func TestImportInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"P7\n1 1\n255\n",
+		"P3\n0 1\n255\n",
+		"P3\n1 1\n0\n0 0 0",
+		"P3\n1 1\n65536\n0 0 0",
+		"P3\n1 1\n255\n0 0",
+		"P3\n1 1\n255\n0 256 0",
+		"P3\n1 1\n255\n0 x 0",
+		"P6\n1 1\n255\n\x00\x00",
+		"P6\n1 1\n255#\x00\x00\x00",
+		"P1\n2 1\n1 2",
+		"P4\n9 1\n\x00",
+		"P5 4611686018427387905 2 255\n",
+		"P5 4611686018427387904 4 255\n",
+		"P5 16385 8192 255\n",
+	}
+	for i, pnm := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := Import(strings.NewReader(pnm)); err == nil {
+				t.Errorf("Import() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestImport_Truncated(t *testing.T) {
+	// the header promises 2 GiB, but the file contains no pixels
+	tests := []string{
+		"P6 16384 8192 255\n",
+		"P3 16384 8192 255\n",
+		"P1 16384 8192\n",
+		"P4 16384 8192\n",
+	}
+	for i, pnm := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			allocated := allocatedBytes(func() {
+				_, err = Import(strings.NewReader(pnm))
+			})
+
+			if err == nil {
+				t.Errorf("Import() error = nil, want an error")
+			}
+
+			if allocated > 1<<20 {
+				t.Errorf("Import() allocated %d bytes, want at most 1 MiB", allocated)
+			}
+		})
+	}
+}
+
+func TestImport_MaxPixels(t *testing.T) {
+	defer func(old int) { MaxPixels = old }(MaxPixels)
+	MaxPixels = 4
+
+	if _, err := Import(strings.NewReader("P1\n2 2\n0000")); err != nil {
+		t.Errorf("Import() error = %v, want nil", err)
+	}
+
+	if _, err := Import(strings.NewReader("P1\n5 1\n00000")); err == nil {
+		t.Errorf("Import() error = nil, want an error")
+	}
+}
+
+// allocatedBytes returns the number of bytes, which have been allocated by f.
+func allocatedBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestImport_RoundTrip(t *testing.T) {
+	c := NewCanvas(13, 7)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			color := math.NewRGB(float32(x)/float32(c.Width-1), float32(y)/float32(c.Height-1), 0.3)
+			c.Write(x, y, &color)
+		}
+	}
+
+	tests := []ExportOptions{
+		{},
+		{Format: BinaryPPM},
+		{MaxVal: 65535},
+		{Format: BinaryPPM, MaxVal: 65535},
+		{Format: BinaryPPM, MaxVal: 1000},
+	}
+	for i, opts := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := &bytes.Buffer{}
+			if err := c.ExportWith(tmp, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := Import(tmp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Width != c.Width || res.Height != c.Height {
+				t.Fatalf("size = %dx%d, want %dx%d", res.Width, res.Height, c.Width, c.Height)
+			}
+
+			// the quantization error is at most half a step
+			maxVal := opts.MaxVal
+			if maxVal == 0 {
+				maxVal = 255
+			}
+
+			tolerance := 0.5/float32(maxVal) + 1e-6
+			for j := range c.Buffer {
+				a, b := &c.Buffer[j], &res.Buffer[j]
+				if math.Abs(a.X-b.X) > tolerance || math.Abs(a.Y-b.Y) > tolerance ||
+					math.Abs(a.Z-b.Z) > tolerance || a.W != b.W {
+					t.Fatalf("Buffer[%d] = %v, want %v", j, *b, *a)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import "github.com/torbenschinke/rtc/math"
+
+// An OutputTransform converts the linear colors of the canvas into the encoding of the output device.
+type OutputTransform int
+
+const (
+	// OutputLinear writes the linear values without a transfer function. This is only correct for
+	// formats and viewers, which expect linear data.
+	OutputLinear OutputTransform = iota
+	// OutputSRGB applies the sRGB transfer function, which is what most viewers assume for 8 bit images.
+	OutputSRGB
+	// OutputRec709 applies the Rec. 709 transfer function of HD video.
+	OutputRec709
+	// OutputDisplayP3 converts the sRGB primaries into the wider Display P3 gamut and applies the sRGB
+	// transfer function.
+	OutputDisplayP3
+)
+
+// apply clamps the linear color to [0, 1] and encodes it. Alpha is only clamped.
+func (o OutputTransform) apply(v *math.Vec4f) {
+	if o == OutputDisplayP3 {
+		v.SRGBToDisplayP3()
+	}
+
+	v.Saturate()
+	switch o {
+	case OutputSRGB, OutputDisplayP3:
+		v.EncodeSRGB()
+	case OutputRec709:
+		v.EncodeRec709()
+	}
+}
+
+func (o OutputTransform) valid() bool {
+	return o >= OutputLinear && o <= OutputDisplayP3
+}
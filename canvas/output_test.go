@@ -0,0 +1,58 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestCanvas_ExportWith_Output(t *testing.T) {
+	tests := []struct {
+		output OutputTransform
+		want   string
+	}{
+		{OutputLinear, "P3\n2 1\n255\n128 255 0 255 0 0\n\n"},
+		{OutputSRGB, "P3\n2 1\n255\n188 255 0 255 0 0\n\n"},
+		{OutputRec709, "P3\n2 1\n255\n180 255 0 255 0 0\n\n"},
+		{OutputDisplayP3, "P3\n2 1\n255\n227 255 0 234 51 35\n\n"},
+	}
+	c := NewCanvas(2, 1)
+	c1 := math.NewRGB(0.5, 2, -1)
+	c.Write(0, 0, &c1)
+	c2 := math.NewRGB(1, 0, 0)
+	c.Write(1, 0, &c2)
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := &bytes.Buffer{}
+			if err := c.ExportWith(tmp, ExportOptions{Output: tt.output}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := tmp.String(); got != tt.want {
+				t.Errorf("ExportWith() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanvas_ExportWith_InvalidOutput(t *testing.T) {
+	c := NewCanvas(1, 1)
+	if err := c.ExportWith(&bytes.Buffer{}, ExportOptions{Output: OutputDisplayP3 + 1}); err == nil {
+		t.Errorf("ExportWith() error = nil, want an error")
+	}
+}
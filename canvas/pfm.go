@@ -0,0 +1,148 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/torbenschinke/rtc/math"
+	"io"
+	stdmath "math"
+	"strconv"
+	"strings"
+)
+
+// ExportPFM writes the buffer as a color Portable FloatMap (PF). In contrast to Export, the colors are
+// neither clamped nor quantized, so all HDR information is kept. The alpha channel is dropped. The
+// values are written in little endian order, from the bottom row to the top row as required by the format.
+func (c *Canvas) ExportPFM(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	// a negative scale declares little endian
+	if _, err := fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", c.Width, c.Height); err != nil {
+		return err
+	}
+
+	row := make([]byte, c.Width*3*4)
+	for y := c.Height - 1; y >= 0; y-- {
+		for x := 0; x < c.Width; x++ {
+			v := c.Read(x, y)
+			binary.LittleEndian.PutUint32(row[x*12:], stdmath.Float32bits(v.X))
+			binary.LittleEndian.PutUint32(row[x*12+4:], stdmath.Float32bits(v.Y))
+			binary.LittleEndian.PutUint32(row[x*12+8:], stdmath.Float32bits(v.Z))
+		}
+
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ImportPFM reads a color (PF) or grayscale (Pf) Portable FloatMap in either byte order. The values are
+// taken as they are, so the magnitude of the scale factor in the header is ignored. The alpha is always 1.
+// Images with more than MaxPixels pixels are rejected.
+func ImportPFM(r io.Reader) (Canvas, error) {
+	c, err := importPFM(bufio.NewReader(r))
+	if err != nil {
+		return Canvas{}, fmt.Errorf("canvas: invalid pfm: %w", err)
+	}
+
+	return c, nil
+}
+
+func importPFM(r *bufio.Reader) (Canvas, error) {
+	d := &pnmDecoder{r: r}
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return Canvas{}, unexpectedEOF(err)
+	}
+
+	var channels int
+	switch string(magic) {
+	case "PF":
+		channels = 3
+	case "Pf":
+		channels = 1
+	default:
+		return Canvas{}, fmt.Errorf("unknown magic number %q", magic)
+	}
+
+	width, err := d.readHeaderInt()
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	height, err := d.readHeaderInt()
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	if err := checkSize(width, height); err != nil {
+		return Canvas{}, err
+	}
+
+	if err := d.skipSpaceAndComments(); err != nil {
+		return Canvas{}, unexpectedEOF(err)
+	}
+
+	// the scale is terminated by a single whitespace, which is followed by the raster
+	token, err := r.ReadString('\n')
+	if err != nil {
+		return Canvas{}, unexpectedEOF(err)
+	}
+
+	scale, err := strconv.ParseFloat(strings.TrimSpace(token), 32)
+	if err != nil {
+		return Canvas{}, fmt.Errorf("invalid scale: %w", err)
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+
+	// the pixels are appended, as they are read, and the rows are flipped afterwards
+	c := Canvas{Width: width, Height: height}
+	var px [12]byte
+	var v [3]float32
+	for i := 0; i < width*height; i++ {
+		if _, err := io.ReadFull(r, px[:channels*4]); err != nil {
+			return Canvas{}, unexpectedEOF(err)
+		}
+
+		for ch := 0; ch < channels; ch++ {
+			v[ch] = stdmath.Float32frombits(order.Uint32(px[ch*4:]))
+		}
+
+		if channels == 1 {
+			v[1], v[2] = v[0], v[0]
+		}
+
+		c.Buffer = append(c.Buffer, math.NewRGB(v[0], v[1], v[2]))
+	}
+
+	// the bottom row comes first
+	for y := 0; y < height/2; y++ {
+		top := c.Buffer[y*width : (y+1)*width]
+		bottom := c.Buffer[(height-1-y)*width : (height-y)*width]
+		for x := range top {
+			top[x], bottom[x] = bottom[x], top[x]
+		}
+	}
+
+	return c, nil
+}
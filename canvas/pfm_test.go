@@ -0,0 +1,141 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCanvas_ExportPFM(t *testing.T) {
+	c := NewCanvas(2, 2)
+	c1 := math.NewRGB(1, 2, -0.5)
+	c.Write(0, 1, &c1)
+
+	tmp := &bytes.Buffer{}
+	if err := c.ExportPFM(tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bottom row comes first
+	want := "PF\n2 2\n-1.0\n" +
+		"\x00\x00\x80\x3f\x00\x00\x00\x40\x00\x00\x00\xbf" + strings.Repeat("\x00", 12) +
+		strings.Repeat("\x00", 24)
+	if got := tmp.String(); got != want {
+		t.Errorf("ExportPFM() = %q, want %q", got, want)
+	}
+}
+
+func TestImportPFM(t *testing.T) {
+	tests := []struct {
+		pfm string
+		res []math.Vec4f
+	}{
+		{"PF\n1 1\n-1.0\n\x00\x00\x80\x3f\x00\x00\x00\x40\x00\x00\x00\xbf", []math.Vec4f{math.NewRGB(1, 2, -0.5)}},
+		{"PF\n1 1\n1.0\n\x3f\x80\x00\x00\x40\x00\x00\x00\xbf\x00\x00\x00", []math.Vec4f{math.NewRGB(1, 2, -0.5)}},
+		{
+			"Pf\n2 2\n4.0\n\x3f\x80\x00\x00\x40\x00\x00\x00\x40\x40\x00\x00\x40\x80\x00\x00",
+			[]math.Vec4f{math.NewRGB(3, 3, 3), math.NewRGB(4, 4, 4), math.NewRGB(1, 1, 1), math.NewRGB(2, 2, 2)},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c, err := ImportPFM(strings.NewReader(tt.pfm))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for j := range tt.res {
+				if c.Buffer[j] != tt.res[j] {
+					t.Errorf("Buffer[%d] = %v, want %v", j, c.Buffer[j], tt.res[j])
+				}
+			}
+		})
+	}
+}
+
+func TestImportPFMInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"P6\n1 1\n-1.0\n",
+		"PF\n0 1\n-1.0\n",
+		"PF\n1 1\nx\n",
+		"PF\n1 1\n-1.0\n\x00\x00\x80\x3f",
+		"PF\n4611686018427387905 2\n-1.0\n",
+		"PF\n2 4611686018427387904\n-1.0\n",
+	}
+	for i, pfm := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := ImportPFM(strings.NewReader(pfm)); err == nil {
+				t.Errorf("ImportPFM() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestPFM_RoundTrip(t *testing.T) {
+	c := NewCanvas(7, 5)
+	for i := range c.Buffer {
+		c.Buffer[i] = math.NewRGB(float32(i)*1234.5, -float32(i)/7, 1e-7*float32(i))
+	}
+
+	tmp := &bytes.Buffer{}
+	if err := c.ExportPFM(tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ImportPFM(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Width != c.Width || res.Height != c.Height {
+		t.Fatalf("size = %dx%d, want %dx%d", res.Width, res.Height, c.Width, c.Height)
+	}
+
+	// lossless
+	for i := range c.Buffer {
+		if res.Buffer[i] != c.Buffer[i] {
+			t.Errorf("Buffer[%d] = %v, want %v", i, res.Buffer[i], c.Buffer[i])
+		}
+	}
+}
+
+func TestImportPFM_Truncated(t *testing.T) {
+	// the header promises up to 2 GiB, but the file contains no pixels
+	tests := []string{
+		"PF\n16384 8192\n-1.0\n",
+		"Pf\n134217728 1\n-1.0\n",
+	}
+	for i, data := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			allocated := allocatedBytes(func() {
+				_, err = ImportPFM(strings.NewReader(data))
+			})
+
+			if err == nil {
+				t.Errorf("ImportPFM() error = nil, want an error")
+			}
+
+			if allocated > 1<<20 {
+				t.Errorf("ImportPFM() allocated %d bytes, want at most 1 MiB", allocated)
+			}
+		})
+	}
+}
@@ -15,43 +15,102 @@
 package canvas
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"math"
 	"strconv"
 )
 
+// A PPMFormat selects the encoding of the pixel values.
+type PPMFormat int
+
+const (
+	// PlainPPM writes the values as decimal text (P3), which is human readable but large.
+	PlainPPM PPMFormat = iota
+	// BinaryPPM writes the values as raw bytes (P6), using two bytes in big endian order if the
+	// maximum value is larger than 255.
+	BinaryPPM
+)
+
+// ExportOptions configures the ppm export. The zero value writes plain ppm with 8 bit per channel.
+type ExportOptions struct {
+	Format     PPMFormat
+	MaxVal     int             // the value of a saturated channel from 1 to 65535, 255 if zero. Use 65535 for 16 bit.
+	ToneMapper ToneMapper      // applied to each pixel before clamping or nil
+	Output     OutputTransform // the encoding of the written values, linear if zero
+}
+
 // Export writes the buffer into a ppm (Portable Pixmap) format in plain PPM.
 func (c *Canvas) Export(w io.Writer) error {
-	ppm := newPPM(w)
-	ppm.WriteHeader(c.Width, c.Height, 255)
+	return c.ExportWith(w, ExportOptions{})
+}
+
+// ExportWith writes the buffer into a ppm (Portable Pixmap) format as configured by the options. The colors
+// are tone mapped, if a ToneMapper is set, clamped to [0, 1], encoded by the output transform and scaled
+// to the maximum value.
+func (c *Canvas) ExportWith(w io.Writer, opts ExportOptions) error {
+	maxVal := opts.MaxVal
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	if maxVal < 1 || maxVal > 65535 {
+		return fmt.Errorf("canvas: maxval %d out of range [1, 65535]", maxVal)
+	}
+
+	if opts.Format != PlainPPM && opts.Format != BinaryPPM {
+		return fmt.Errorf("canvas: unsupported ppm format %d", opts.Format)
+	}
+
+	if !opts.Output.valid() {
+		return fmt.Errorf("canvas: unsupported output transform %d", opts.Output)
+	}
+
+	bw := bufio.NewWriter(w)
+	ppm := newPPM(bw, opts.Format == BinaryPPM, maxVal)
+	ppm.WriteHeader(c.Width, c.Height, maxVal)
 
 	// actual pixel values
+	scale := float32(maxVal)
 	for y := 0; y < c.Height; y++ {
 		for x := 0; x < c.Width; x++ {
 			v := *c.Read(x, y)
-			v.Saturate()
-			v.Mul(255)
+			if opts.ToneMapper != nil {
+				opts.ToneMapper.Map(&v)
+			}
+
+			opts.Output.apply(&v)
+			v.Mul(scale)
 			ppm.WritePixel(v.X, v.Y, v.Z)
 		}
 
 		ppm.EndRow()
 	}
 
-	return ppm.Close()
+	if err := ppm.Close(); err != nil {
+		return err
+	}
+
+	return bw.Flush()
 }
 
 type ppm struct {
 	w                 io.Writer
 	err               error
+	binary            bool
+	wide              bool // two bytes per binary value
+	buf               [2]byte
 	maxLineLength     int
 	currentLineLength int
 	hadPixelInLine    bool
 }
 
-func newPPM(w io.Writer) *ppm {
+func newPPM(w io.Writer, binary bool, maxVal int) *ppm {
 	return &ppm{
 		w:             w,
+		binary:        binary,
+		wide:          maxVal > 255,
 		maxLineLength: 70,
 	}
 }
@@ -71,7 +130,13 @@ func (p *ppm) Printf(format string, args ...interface{}) {
 }
 
 func (p *ppm) writeNum(f float32) {
-	v := strconv.Itoa(int(math.RoundToEven(float64(f))))
+	n := int(math.RoundToEven(float64(f)))
+	if p.binary {
+		p.writeBinary(n)
+		return
+	}
+
+	v := strconv.Itoa(n)
 	if p.currentLineLength+len(v) >= p.maxLineLength {
 		p.currentLineLength = 0
 		p.Printf("\n")
@@ -87,11 +152,33 @@ func (p *ppm) writeNum(f float32) {
 	p.hadPixelInLine = true
 }
 
+func (p *ppm) writeBinary(n int) {
+	if p.err != nil {
+		return
+	}
+
+	b := p.buf[:1]
+	if p.wide {
+		b = p.buf[:2]
+		b[0] = byte(n >> 8)
+		b[1] = byte(n)
+	} else {
+		b[0] = byte(n)
+	}
+
+	_, p.err = p.w.Write(b)
+}
+
 // WriteHeader emits the header bytes.
 func (p *ppm) WriteHeader(width, height, max int) {
-	p.Printf("P3\n")
+	if p.binary {
+		p.Printf("P6\n")
+	} else {
+		p.Printf("P3\n")
+	}
+
 	p.Printf("%d %d\n", width, height)
-	p.Printf("255\n")
+	p.Printf("%d\n", max)
 }
 
 func (p *ppm) WritePixel(r, g, b float32) {
@@ -101,12 +188,20 @@ func (p *ppm) WritePixel(r, g, b float32) {
 }
 
 func (p *ppm) EndRow() {
+	if p.binary {
+		return
+	}
+
 	p.Printf("\n")
 	p.currentLineLength = 0
 	p.hadPixelInLine = false
 }
 
 func (p *ppm) Close() error {
+	if p.binary {
+		return p.Error()
+	}
+
 	if p.hadPixelInLine {
 		p.EndRow()
 	}
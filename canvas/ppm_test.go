@@ -102,3 +102,52 @@ func TestCanvas_Export(t *testing.T) {
 		})
 	}
 }
+
+func TestCanvas_ExportWith(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c1 := math.NewRGB(1, 0.5, 0)
+	c2 := math.NewRGB(-1, 0.25, 2)
+	c.Write(0, 0, &c1)
+	c.Write(1, 0, &c2)
+
+	tests := []struct {
+		opts ExportOptions
+		ppm  string
+	}{
+		{ExportOptions{Format: BinaryPPM}, "P6\n2 1\n255\n\xff\x80\x00\x00\x40\xff"},
+		{
+			ExportOptions{Format: BinaryPPM, MaxVal: 65535},
+			"P6\n2 1\n65535\n\xff\xff\x80\x00\x00\x00\x00\x00\x40\x00\xff\xff",
+		},
+		{ExportOptions{MaxVal: 65535}, "P3\n2 1\n65535\n65535 32768 0 0 16384 65535\n\n"},
+		{ExportOptions{MaxVal: 1}, "P3\n2 1\n1\n1 0 0 0 0 1\n\n"},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := &bytes.Buffer{}
+			if err := c.ExportWith(tmp, tt.opts); err != nil {
+				t.Fatalf("ExportWith() error = %v", err)
+			}
+
+			if got := tmp.String(); got != tt.ppm {
+				t.Errorf("ExportWith() = %q, want %q", got, tt.ppm)
+			}
+		})
+	}
+}
+
+func TestCanvas_ExportWithInvalid(t *testing.T) {
+	tests := []ExportOptions{
+		{MaxVal: -1},
+		{MaxVal: 65536},
+		{Format: PPMFormat(42)},
+	}
+	for i, opts := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCanvas(1, 1)
+			if err := c.ExportWith(&bytes.Buffer{}, opts); err == nil {
+				t.Errorf("ExportWith() error = nil, want an error")
+			}
+		})
+	}
+}
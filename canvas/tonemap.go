@@ -0,0 +1,135 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import "github.com/torbenschinke/rtc/math"
+
+// A ToneMapper compresses the unbounded linear colors of a render into the displayable range, so that
+// bright areas keep their details instead of being clipped to white.
+type ToneMapper interface {
+	// Map replaces the color channels of c with the mapped values. Alpha is left untouched.
+	Map(c *math.Vec4f)
+}
+
+// Reinhard maps each channel by c / (1 + c), which approaches but never reaches 1.
+type Reinhard struct{}
+
+// Map applies the operator to each color channel.
+func (Reinhard) Map(c *math.Vec4f) {
+	mapRGB(c, func(x float32) float32 {
+		return x / (1 + x)
+	})
+}
+
+// ExtendedReinhard is the Reinhard operator, which maps the White value and everything above to 1.
+// A zero White behaves like Reinhard.
+type ExtendedReinhard struct {
+	White float32
+}
+
+// Map applies the operator to each color channel.
+func (r ExtendedReinhard) Map(c *math.Vec4f) {
+	if r.White == 0 {
+		Reinhard{}.Map(c)
+		return
+	}
+
+	w2 := r.White * r.White
+	mapRGB(c, func(x float32) float32 {
+		return x * (1 + x/w2) / (1 + x)
+	})
+}
+
+// ACES is the curve fitted by Krzysztof Narkowicz to the ACES filmic reference, which gives a high
+// contrast and saturated look.
+type ACES struct{}
+
+// Map applies the operator to each color channel.
+func (ACES) Map(c *math.Vec4f) {
+	mapRGB(c, func(x float32) float32 {
+		return (x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14)
+	})
+}
+
+// Uncharted2 is the filmic curve by John Hable, which was used in Uncharted 2.
+type Uncharted2 struct {
+	ExposureBias float32 // scales the input, 2 if zero
+	White        float32 // the linear value which maps to 1, 11.2 if zero
+}
+
+// Map applies the operator to each color channel.
+func (u Uncharted2) Map(c *math.Vec4f) {
+	bias := u.ExposureBias
+	if bias == 0 {
+		bias = 2
+	}
+
+	white := u.White
+	if white == 0 {
+		white = 11.2
+	}
+
+	scale := 1 / hable(white)
+	mapRGB(c, func(x float32) float32 {
+		return hable(bias*x) * scale
+	})
+}
+
+// hable is the partial curve of Uncharted2.
+func hable(x float32) float32 {
+	const (
+		a = 0.15 // shoulder strength
+		b = 0.50 // linear strength
+		c = 0.10 // linear angle
+		d = 0.20 // toe strength
+		e = 0.02 // toe numerator
+		f = 0.30 // toe denominator
+	)
+
+	return (x*(a*x+c*b)+d*e)/(x*(a*x+b)+d*f) - e/f
+}
+
+// Exposure scales the colors by 2^EV, like opening the aperture of a camera by EV stops, and applies
+// the Next operator afterwards, if not nil.
+type Exposure struct {
+	EV   float32
+	Next ToneMapper
+}
+
+// Map scales the color channels and applies the next operator.
+func (e Exposure) Map(c *math.Vec4f) {
+	scale := math.Pow(2, e.EV)
+	c.X *= scale
+	c.Y *= scale
+	c.Z *= scale
+	if e.Next != nil {
+		e.Next.Map(c)
+	}
+}
+
+// mapRGB applies f to the color channels. Negative values are invalid for all operators and become 0.
+func mapRGB(c *math.Vec4f, f func(x float32) float32) {
+	c.X = f(math.Max(c.X, 0))
+	c.Y = f(math.Max(c.Y, 0))
+	c.Z = f(math.Max(c.Z, 0))
+}
+
+// ToneMap applies the operator to each pixel in place. This is useful before the canvas is encoded by
+// a format without tone mapping support, like through the Image adapter.
+func (c *Canvas) ToneMap(tm ToneMapper) {
+	for i := range c.Buffer {
+		tm.Map(&c.Buffer[i])
+	}
+}
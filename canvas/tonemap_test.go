@@ -0,0 +1,101 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canvas
+
+import (
+	"bytes"
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestToneMapper_Map(t *testing.T) {
+	tests := []struct {
+		tm   ToneMapper
+		in   math.Vec4f
+		want math.Vec4f
+	}{
+		{Reinhard{}, math.NewRGBA(0, 1, 3, 0.5), math.NewRGBA(0, 0.5, 0.75, 0.5)},
+		{Reinhard{}, math.NewRGB(-1, 1e6, 1), math.NewRGB(0, 0.999999, 0.5)},
+		{ExtendedReinhard{White: 2}, math.NewRGB(0, 1, 2), math.NewRGB(0, 0.625, 1)},
+		{ExtendedReinhard{}, math.NewRGB(0, 1, 3), math.NewRGB(0, 0.5, 0.75)},
+		{ACES{}, math.NewRGB(0, 1, -1), math.NewRGB(0, 0.80380, 0)},
+		{Uncharted2{}, math.NewRGB(0, 5.6, -1), math.NewRGB(0, 1, 0)},
+		{Uncharted2{ExposureBias: 1, White: 4}, math.NewRGB(0, 4, 0), math.NewRGB(0, 1, 0)},
+		{Exposure{EV: 1}, math.NewRGBA(0.25, 1, 2, 0.5), math.NewRGBA(0.5, 2, 4, 0.5)},
+		{Exposure{EV: -1, Next: Reinhard{}}, math.NewRGB(2, 6, 0), math.NewRGB(0.5, 0.75, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := tt.in
+			tt.tm.Map(&got)
+			if !got.Equals(&tt.want) {
+				t.Errorf("Map() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToneMapper_Monotonic(t *testing.T) {
+	tests := []ToneMapper{Reinhard{}, ExtendedReinhard{White: 4}, ACES{}, Uncharted2{}}
+	for i, tm := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			prev := float32(-1)
+			for x := float32(0); x < 4; x += 0.01 {
+				v := math.NewRGB(x, x, x)
+				tm.Map(&v)
+				if v.X <= prev {
+					t.Fatalf("Map(%v) = %v, want more than %v", x, v.X, prev)
+				}
+
+				prev = v.X
+			}
+		})
+	}
+}
+
+func TestCanvas_ExportWith_ToneMapper(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c1 := math.NewRGB(1, 3, 255)
+	c.Write(0, 0, &c1)
+
+	tmp := &bytes.Buffer{}
+	if err := c.ExportWith(tmp, ExportOptions{ToneMapper: Reinhard{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "P3\n2 1\n255\n128 191 254 0 0 0\n\n"
+	if got := tmp.String(); got != want {
+		t.Errorf("ExportWith() = %q, want %q", got, want)
+	}
+
+	// the canvas itself is not modified
+	if got := *c.Read(0, 0); got != c1 {
+		t.Errorf("Read() = %v, want %v", got, c1)
+	}
+}
+
+func TestCanvas_ToneMap(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.Clear(math.NewRGBA(1, 1, 1, 1))
+	c.ToneMap(Reinhard{})
+
+	want := math.NewRGBA(0.5, 0.5, 0.5, 1)
+	for i, v := range c.Buffer {
+		if v != want {
+			t.Errorf("Buffer[%d] = %v, want %v", i, v, want)
+		}
+	}
+}
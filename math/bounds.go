@@ -0,0 +1,175 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// A BoundingBox is an axis aligned box, which encloses a shape. Its bounds may be infinite, e.g.
+// for a plane. A box whose minimum is larger than its maximum is empty.
+type BoundingBox struct {
+	Min, Max Vec4f // points
+}
+
+// NewBoundingBox creates a box from the given corner points.
+func NewBoundingBox(min, max Vec4f) BoundingBox {
+	return BoundingBox{Min: min, Max: max}
+}
+
+// EmptyBoundingBox creates a box, which contains nothing. Adding a point or merging a box
+// results in a box which contains exactly that.
+func EmptyBoundingBox() BoundingBox {
+	return BoundingBox{
+		Min: NewPoint(Inf(1), Inf(1), Inf(1)),
+		Max: NewPoint(Inf(-1), Inf(-1), Inf(-1)),
+	}
+}
+
+// IsEmpty returns true, if the box contains nothing.
+func (b *BoundingBox) IsEmpty() bool {
+	return b.Min.X > b.Max.X || b.Min.Y > b.Max.Y || b.Min.Z > b.Max.Z
+}
+
+// AddPoint grows the box, so that it contains the point.
+func (b *BoundingBox) AddPoint(p *Vec4f) {
+	b.Min.X = Min(b.Min.X, p.X)
+	b.Min.Y = Min(b.Min.Y, p.Y)
+	b.Min.Z = Min(b.Min.Z, p.Z)
+	b.Max.X = Max(b.Max.X, p.X)
+	b.Max.Y = Max(b.Max.Y, p.Y)
+	b.Max.Z = Max(b.Max.Z, p.Z)
+}
+
+// Merge grows the box, so that it contains the other box.
+func (b *BoundingBox) Merge(o *BoundingBox) {
+	if o.IsEmpty() {
+		return
+	}
+
+	b.AddPoint(&o.Min)
+	b.AddPoint(&o.Max)
+}
+
+// SurfaceArea returns the area of all six faces, which is 0 for an empty box.
+func (b *BoundingBox) SurfaceArea() float32 {
+	if b.IsEmpty() {
+		return 0
+	}
+
+	dx := b.Max.X - b.Min.X
+	dy := b.Max.Y - b.Min.Y
+	dz := b.Max.Z - b.Min.Z
+	return 2 * (dx*dy + dx*dz + dy*dz)
+}
+
+// ContainsPoint returns true, if the point is inside or on the surface of the box.
+func (b *BoundingBox) ContainsPoint(p *Vec4f) bool {
+	return b.Min.X <= p.X && p.X <= b.Max.X &&
+		b.Min.Y <= p.Y && p.Y <= b.Max.Y &&
+		b.Min.Z <= p.Z && p.Z <= b.Max.Z
+}
+
+// ContainsBox returns true, if the other box is completely inside of the box.
+func (b *BoundingBox) ContainsBox(o *BoundingBox) bool {
+	return b.ContainsPoint(&o.Min) && b.ContainsPoint(&o.Max)
+}
+
+// Transform applies the matrix and replaces the box by the axis aligned box, which encloses the
+// transformed box. The result is usually larger than the transformed box, e.g. after a rotation.
+func (b *BoundingBox) Transform(m *Mat4f) {
+	if b.IsEmpty() {
+		return
+	}
+
+	// Instead of transforming all 8 corners, each resulting axis is the translation plus the sum of the
+	// smaller and the larger products of each column (Arvo). Zero factors are skipped, because
+	// 0 * Inf is NaN but an infinite extent does not spread into an axis, which does not depend on it.
+	min := [3]float32{b.Min.X, b.Min.Y, b.Min.Z}
+	max := [3]float32{b.Max.X, b.Max.Y, b.Max.Z}
+	var resMin, resMax [3]float32
+	for i := 0; i < 3; i++ {
+		resMin[i] = m[i][3]
+		resMax[i] = m[i][3]
+		for j := 0; j < 3; j++ {
+			if m[i][j] == 0 {
+				continue
+			}
+
+			e := m[i][j] * min[j]
+			f := m[i][j] * max[j]
+			resMin[i] += Min(e, f)
+			resMax[i] += Max(e, f)
+		}
+	}
+
+	b.Min = NewPoint(resMin[0], resMin[1], resMin[2])
+	b.Max = NewPoint(resMax[0], resMax[1], resMax[2])
+}
+
+// Intersects returns true, if the ray hits the box, regardless if the hit is in front of or behind the
+// ray origin. It uses the same slab test as a cube.
+func (b *BoundingBox) Intersects(r *Ray) bool {
+	if b.IsEmpty() {
+		return false
+	}
+
+	xtmin, xtmax := slab(r.Origin.X, r.Direction.X, b.Min.X, b.Max.X)
+	ytmin, ytmax := slab(r.Origin.Y, r.Direction.Y, b.Min.Y, b.Max.Y)
+	ztmin, ztmax := slab(r.Origin.Z, r.Direction.Z, b.Min.Z, b.Max.Z)
+
+	tmin := Max(xtmin, Max(ytmin, ztmin))
+	tmax := Min(xtmax, Min(ytmax, ztmax))
+	return tmin <= tmax
+}
+
+// slab returns the distances, at which the ray crosses the two planes at min and max of a single axis.
+func slab(origin, direction, min, max float32) (tmin, tmax float32) {
+	if Abs(direction) < Epsilon {
+		// parallel to the planes, so the ray is either always in between or never
+		if origin < min || origin > max {
+			return Inf(1), Inf(-1)
+		}
+
+		return Inf(-1), Inf(1)
+	}
+
+	tmin = (min - origin) / direction
+	tmax = (max - origin) / direction
+	if tmin > tmax {
+		tmin, tmax = tmax, tmin
+	}
+
+	return tmin, tmax
+}
+
+// Split divides the box in halves, perpendicular to its largest dimension.
+func (b *BoundingBox) Split() (left, right BoundingBox) {
+	dx := b.Max.X - b.Min.X
+	dy := b.Max.Y - b.Min.Y
+	dz := b.Max.Z - b.Min.Z
+
+	leftMax := b.Max
+	rightMin := b.Min
+	switch Max(dx, Max(dy, dz)) {
+	case dx:
+		leftMax.X = b.Min.X + dx/2
+		rightMin.X = leftMax.X
+	case dy:
+		leftMax.Y = b.Min.Y + dy/2
+		rightMin.Y = leftMax.Y
+	default:
+		leftMax.Z = b.Min.Z + dz/2
+		rightMin.Z = leftMax.Z
+	}
+
+	return NewBoundingBox(b.Min, leftMax), NewBoundingBox(rightMin, b.Max)
+}
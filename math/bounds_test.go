@@ -0,0 +1,212 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBoundingBox_AddPoint(t *testing.T) {
+	b := EmptyBoundingBox()
+	if !b.IsEmpty() {
+		t.Errorf("IsEmpty() = false, want true")
+	}
+
+	p1 := NewPoint(-5, 2, 0)
+	p2 := NewPoint(7, 0, -3)
+	b.AddPoint(&p1)
+	b.AddPoint(&p2)
+
+	want := NewBoundingBox(NewPoint(-5, 0, -3), NewPoint(7, 2, 0))
+	if b.IsEmpty() || !b.Min.Equals(&want.Min) || !b.Max.Equals(&want.Max) {
+		t.Errorf("box = %v, want %v", b, want)
+	}
+}
+
+func TestBoundingBox_Merge(t *testing.T) {
+	b := NewBoundingBox(NewPoint(-5, -2, 0), NewPoint(7, 4, 4))
+	o := NewBoundingBox(NewPoint(8, -7, -2), NewPoint(14, 2, 8))
+	b.Merge(&o)
+
+	want := NewBoundingBox(NewPoint(-5, -7, -2), NewPoint(14, 4, 8))
+	if !b.Min.Equals(&want.Min) || !b.Max.Equals(&want.Max) {
+		t.Errorf("box = %v, want %v", b, want)
+	}
+
+	// an empty box changes nothing
+	empty := EmptyBoundingBox()
+	b.Merge(&empty)
+	if !b.Min.Equals(&want.Min) || !b.Max.Equals(&want.Max) {
+		t.Errorf("box = %v, want %v", b, want)
+	}
+}
+
+func TestBoundingBox_SurfaceArea(t *testing.T) {
+	tests := []struct {
+		box BoundingBox
+		res float32
+	}{
+		{EmptyBoundingBox(), 0},
+		{NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1)), 24},
+		{NewBoundingBox(NewPoint(0, 0, 0), NewPoint(1, 2, 3)), 22},
+		{NewBoundingBox(NewPoint(0, 0, 0), NewPoint(1, 2, 0)), 4},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := tt.box.SurfaceArea(); got != tt.res {
+				t.Errorf("SurfaceArea() = %v, want %v", got, tt.res)
+			}
+		})
+	}
+}
+
+func TestBoundingBox_ContainsPoint(t *testing.T) {
+	b := NewBoundingBox(NewPoint(5, -2, 0), NewPoint(11, 4, 7))
+	tests := []struct {
+		point Vec4f
+		res   bool
+	}{
+		{NewPoint(5, -2, 0), true},
+		{NewPoint(11, 4, 7), true},
+		{NewPoint(8, 1, 3), true},
+		{NewPoint(3, 0, 3), false},
+		{NewPoint(8, -4, 3), false},
+		{NewPoint(8, 1, -1), false},
+		{NewPoint(13, 1, 3), false},
+		{NewPoint(8, 5, 3), false},
+		{NewPoint(8, 1, 8), false},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := b.ContainsPoint(&tt.point); got != tt.res {
+				t.Errorf("ContainsPoint(%v) = %v, want %v", tt.point, got, tt.res)
+			}
+		})
+	}
+}
+
+func TestBoundingBox_ContainsBox(t *testing.T) {
+	b := NewBoundingBox(NewPoint(5, -2, 0), NewPoint(11, 4, 7))
+	tests := []struct {
+		min, max Vec4f
+		res      bool
+	}{
+		{NewPoint(5, -2, 0), NewPoint(11, 4, 7), true},
+		{NewPoint(6, -1, 1), NewPoint(10, 3, 6), true},
+		{NewPoint(4, -3, -1), NewPoint(10, 3, 6), false},
+		{NewPoint(6, -1, 1), NewPoint(12, 5, 8), false},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			o := NewBoundingBox(tt.min, tt.max)
+			if got := b.ContainsBox(&o); got != tt.res {
+				t.Errorf("ContainsBox(%v) = %v, want %v", o, got, tt.res)
+			}
+		})
+	}
+}
+
+func TestBoundingBox_Transform(t *testing.T) {
+	b := NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1))
+	m := RotationY(Pi / 4).RotateX(Pi / 4)
+	b.Transform(&m)
+
+	want := NewBoundingBox(NewPoint(-1.41421, -1.70711, -1.70711), NewPoint(1.41421, 1.70711, 1.70711))
+	if !b.Min.Equals(&want.Min) || !b.Max.Equals(&want.Max) {
+		t.Errorf("box = %v, want %v", b, want)
+	}
+
+	// infinite bounds must not become NaN
+	b = NewBoundingBox(NewPoint(Inf(-1), 0, Inf(-1)), NewPoint(Inf(1), 0, Inf(1)))
+	m = Translation(1, 2, 3)
+	b.Transform(&m)
+	if b.Min.X != Inf(-1) || b.Min.Y != 2 || b.Min.Z != Inf(-1) || b.Max.X != Inf(1) || b.Max.Y != 2 || b.Max.Z != Inf(1) {
+		t.Errorf("box = %v", b)
+	}
+}
+
+func TestBoundingBox_Intersects(t *testing.T) {
+	cube := NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1))
+	box := NewBoundingBox(NewPoint(5, -2, 0), NewPoint(11, 4, 7))
+	tests := []struct {
+		box BoundingBox
+		ray Ray
+		res bool
+	}{
+		{cube, NewRay(NewPoint(5, 0.5, 0), NewVector(-1, 0, 0)), true},
+		{cube, NewRay(NewPoint(-5, 0.5, 0), NewVector(1, 0, 0)), true},
+		{cube, NewRay(NewPoint(0.5, 5, 0), NewVector(0, -1, 0)), true},
+		{cube, NewRay(NewPoint(0.5, -5, 0), NewVector(0, 1, 0)), true},
+		{cube, NewRay(NewPoint(0.5, 0, 5), NewVector(0, 0, -1)), true},
+		{cube, NewRay(NewPoint(0.5, 0, -5), NewVector(0, 0, 1)), true},
+		{cube, NewRay(NewPoint(0, 0.5, 0), NewVector(0, 0, 1)), true},
+		{cube, NewRay(NewPoint(-2, 0, 0), NewVector(2, 4, 6)), false},
+		{cube, NewRay(NewPoint(0, -2, 0), NewVector(6, 2, 4)), false},
+		{cube, NewRay(NewPoint(0, 0, -2), NewVector(4, 6, 2)), false},
+		{cube, NewRay(NewPoint(2, 0, 2), NewVector(0, 0, -1)), false},
+		{cube, NewRay(NewPoint(0, 2, 2), NewVector(0, -1, 0)), false},
+		{cube, NewRay(NewPoint(2, 2, 0), NewVector(-1, 0, 0)), false},
+
+		{box, NewRay(NewPoint(15, 1, 2), NewVector(-1, 0, 0)), true},
+		{box, NewRay(NewPoint(-5, -1, 4), NewVector(1, 0, 0)), true},
+		{box, NewRay(NewPoint(7, 6, 5), NewVector(0, -1, 0)), true},
+		{box, NewRay(NewPoint(9, -5, 6), NewVector(0, 1, 0)), true},
+		{box, NewRay(NewPoint(8, 2, 12), NewVector(0, 0, -1)), true},
+		{box, NewRay(NewPoint(6, 0, -5), NewVector(0, 0, 1)), true},
+		{box, NewRay(NewPoint(8, 1, 3.5), NewVector(0, 0, 1)), true},
+		{box, NewRay(NewPoint(9, -1, -8), NewVector(2, 4, 6)), false},
+		{box, NewRay(NewPoint(8, 3, -4), NewVector(6, 2, 4)), false},
+		{box, NewRay(NewPoint(9, -1, -2), NewVector(4, 6, 2)), false},
+		{box, NewRay(NewPoint(4, 0, 9), NewVector(0, 0, -1)), false},
+		{box, NewRay(NewPoint(8, 6, -1), NewVector(0, -1, 0)), false},
+		{box, NewRay(NewPoint(12, 5, 4), NewVector(-1, 0, 0)), false},
+
+		{EmptyBoundingBox(), NewRay(NewPoint(0, 0, 0), NewVector(1, 0, 0)), false},
+		{NewBoundingBox(NewPoint(Inf(-1), 0, Inf(-1)), NewPoint(Inf(1), 0, Inf(1))), NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0)), true},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := tt.box.Intersects(&tt.ray); got != tt.res {
+				t.Errorf("Intersects() = %v, want %v", got, tt.res)
+			}
+		})
+	}
+}
+
+func TestBoundingBox_Split(t *testing.T) {
+	tests := []struct {
+		min, max          Vec4f
+		leftMax, rightMin Vec4f
+	}{
+		{NewPoint(-1, -4, -5), NewPoint(9, 6, 5), NewPoint(4, 6, 5), NewPoint(4, -4, -5)},
+		{NewPoint(-1, -2, -3), NewPoint(9, 5.5, 3), NewPoint(4, 5.5, 3), NewPoint(4, -2, -3)},
+		{NewPoint(-1, -2, -3), NewPoint(5, 8, 3), NewPoint(5, 3, 3), NewPoint(-1, 3, -3)},
+		{NewPoint(-1, -2, -3), NewPoint(5, 3, 7), NewPoint(5, 3, 2), NewPoint(-1, -2, 2)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			b := NewBoundingBox(tt.min, tt.max)
+			left, right := b.Split()
+			if !left.Min.Equals(&tt.min) || !left.Max.Equals(&tt.leftMax) {
+				t.Errorf("left = %v, want %v %v", left, tt.min, tt.leftMax)
+			}
+
+			if !right.Min.Equals(&tt.rightMin) || !right.Max.Equals(&tt.max) {
+				t.Errorf("right = %v, want %v %v", right, tt.rightMin, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// The color functions interpret the X, Y and Z components of a Vec4f as the red, green and blue channels
+// or as the coordinates of a CIE color space. The W component is the alpha value, which is never touched.
+// Linear values are proportional to the light intensity, which is what the tracer computes. Encoded values
+// have a transfer function (gamma) applied, which is what displays and most image formats expect.
+
+// The matrices are taken from CSS Color Module Level 4, whose sRGB and Display P3 matrices share the
+// same white point, so that white maps exactly to white.
+
+// linearSRGBToXYZ converts linear sRGB, which has the same primaries as Rec. 709, into CIE XYZ (D65).
+var linearSRGBToXYZ = Mat3f{
+	{0.4123908, 0.3575843, 0.1804808},
+	{0.2126390, 0.7151687, 0.0721923},
+	{0.0193308, 0.1191948, 0.9505322},
+}
+
+// xyzToLinearSRGB is the inverse of linearSRGBToXYZ.
+var xyzToLinearSRGB = Mat3f{
+	{3.2409699, -1.5373832, -0.4986108},
+	{-0.9692436, 1.8759675, 0.0415551},
+	{0.0556301, -0.2039770, 1.0569715},
+}
+
+// linearDisplayP3ToXYZ converts linear Display P3, which has the DCI-P3 primaries and a D65 white point,
+// into CIE XYZ (D65).
+var linearDisplayP3ToXYZ = Mat3f{
+	{0.4865709, 0.2656677, 0.1982173},
+	{0.2289746, 0.6917385, 0.0792869},
+	{0.0000000, 0.0451134, 1.0439444},
+}
+
+// xyzToLinearDisplayP3 is the inverse of linearDisplayP3ToXYZ.
+var xyzToLinearDisplayP3 = Mat3f{
+	{2.4934969, -0.9313836, -0.4027108},
+	{-0.8294890, 1.7626641, 0.0236247},
+	{0.0358458, -0.0761724, 0.9568845},
+}
+
+// D65x and D65y are the chromaticity of the white point of sRGB, Rec. 709 and Display P3.
+const (
+	D65x = 0.3127
+	D65y = 0.3290
+)
+
+// LinearToSRGB applies the sRGB transfer function (gamma encoding) to a value in [0, 1].
+func LinearToSRGB(v float32) float32 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+
+	return 1.055*Pow(v, 1/2.4) - 0.055
+}
+
+// SRGBToLinear is the inverse of LinearToSRGB.
+func SRGBToLinear(v float32) float32 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToRec709 applies the Rec. 709 transfer function (OETF) to a value in [0, 1].
+func LinearToRec709(v float32) float32 {
+	if v < 0.018 {
+		return v * 4.5
+	}
+
+	return 1.099*Pow(v, 0.45) - 0.099
+}
+
+// Rec709ToLinear is the inverse of LinearToRec709.
+func Rec709ToLinear(v float32) float32 {
+	if v < 0.081 {
+		return v / 4.5
+	}
+
+	return Pow((v+0.099)/1.099, 1/0.45)
+}
+
+// EncodeSRGB applies LinearToSRGB to the color channels. Display P3 uses the same transfer function.
+func (v *Vec4f) EncodeSRGB() {
+	v.X = LinearToSRGB(v.X)
+	v.Y = LinearToSRGB(v.Y)
+	v.Z = LinearToSRGB(v.Z)
+}
+
+// DecodeSRGB applies SRGBToLinear to the color channels.
+func (v *Vec4f) DecodeSRGB() {
+	v.X = SRGBToLinear(v.X)
+	v.Y = SRGBToLinear(v.Y)
+	v.Z = SRGBToLinear(v.Z)
+}
+
+// EncodeRec709 applies LinearToRec709 to the color channels.
+func (v *Vec4f) EncodeRec709() {
+	v.X = LinearToRec709(v.X)
+	v.Y = LinearToRec709(v.Y)
+	v.Z = LinearToRec709(v.Z)
+}
+
+// DecodeRec709 applies Rec709ToLinear to the color channels.
+func (v *Vec4f) DecodeRec709() {
+	v.X = Rec709ToLinear(v.X)
+	v.Y = Rec709ToLinear(v.Y)
+	v.Z = Rec709ToLinear(v.Z)
+}
+
+// SRGBToXYZ converts the linear sRGB or Rec. 709 color into CIE XYZ.
+func (v *Vec4f) SRGBToXYZ() {
+	v.transform3(&linearSRGBToXYZ)
+}
+
+// XYZToSRGB converts the CIE XYZ color into linear sRGB or Rec. 709. Colors outside of the gamut
+// result in channels outside of [0, 1].
+func (v *Vec4f) XYZToSRGB() {
+	v.transform3(&xyzToLinearSRGB)
+}
+
+// DisplayP3ToXYZ converts the linear Display P3 color into CIE XYZ.
+func (v *Vec4f) DisplayP3ToXYZ() {
+	v.transform3(&linearDisplayP3ToXYZ)
+}
+
+// XYZToDisplayP3 converts the CIE XYZ color into linear Display P3. Colors outside of the gamut
+// result in channels outside of [0, 1].
+func (v *Vec4f) XYZToDisplayP3() {
+	v.transform3(&xyzToLinearDisplayP3)
+}
+
+// SRGBToDisplayP3 converts the linear sRGB color into linear Display P3, which has a wider gamut,
+// so that the result is always within [0, 1] for valid input.
+func (v *Vec4f) SRGBToDisplayP3() {
+	v.SRGBToXYZ()
+	v.XYZToDisplayP3()
+}
+
+// DisplayP3ToSRGB is the inverse of SRGBToDisplayP3.
+func (v *Vec4f) DisplayP3ToSRGB() {
+	v.DisplayP3ToXYZ()
+	v.XYZToSRGB()
+}
+
+// XYZToXyY converts CIE XYZ into the chromaticity x and y and the luminance Y, which are stored
+// in X, Y and Z. Black has no chromaticity, so the D65 white point is used.
+func (v *Vec4f) XYZToXyY() {
+	sum := v.X + v.Y + v.Z
+	if sum == 0 {
+		v.X, v.Y, v.Z = D65x, D65y, 0
+		return
+	}
+
+	v.X, v.Y, v.Z = v.X/sum, v.Y/sum, v.Y
+}
+
+// XyYToXYZ is the inverse of XYZToXyY.
+func (v *Vec4f) XyYToXYZ() {
+	x, y, luminance := v.X, v.Y, v.Z
+	if y == 0 {
+		v.X, v.Y, v.Z = 0, 0, 0
+		return
+	}
+
+	v.X, v.Y, v.Z = x*luminance/y, luminance, (1-x-y)*luminance/y
+}
+
+// Premultiply multiplies the color channels with the alpha value. Premultiplied colors can be blended
+// and filtered without producing dark fringes around transparent areas.
+func (v *Vec4f) Premultiply() {
+	v.X *= v.W
+	v.Y *= v.W
+	v.Z *= v.W
+}
+
+// Unpremultiply is the inverse of Premultiply. A fully transparent color has no color channels, so it
+// becomes transparent black.
+func (v *Vec4f) Unpremultiply() {
+	if v.W == 0 {
+		v.X, v.Y, v.Z = 0, 0, 0
+		return
+	}
+
+	v.X /= v.W
+	v.Y /= v.W
+	v.Z /= v.W
+}
+
+// transform3 multiplies the color channels with the matrix.
+func (v *Vec4f) transform3(m *Mat3f) {
+	v.X, v.Y, v.Z =
+		m[0][0]*v.X+m[0][1]*v.Y+m[0][2]*v.Z,
+		m[1][0]*v.X+m[1][1]*v.Y+m[1][2]*v.Z,
+		m[2][0]*v.X+m[2][1]*v.Y+m[2][2]*v.Z
+}
@@ -0,0 +1,200 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSRGB(t *testing.T) {
+	tests := []struct {
+		linear, srgb float32
+	}{
+		{0, 0},
+		{0.001, 0.01292},
+		{0.0031308, 0.04045},
+		{0.21404, 0.5},
+		{0.5, 0.73536},
+		{1, 1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := LinearToSRGB(tt.linear); Abs(got-tt.srgb) > 1e-4 {
+				t.Errorf("LinearToSRGB(%v) = %v, want %v", tt.linear, got, tt.srgb)
+			}
+
+			if got := SRGBToLinear(tt.srgb); Abs(got-tt.linear) > 1e-4 {
+				t.Errorf("SRGBToLinear(%v) = %v, want %v", tt.srgb, got, tt.linear)
+			}
+		})
+	}
+}
+
+func TestRec709(t *testing.T) {
+	tests := []struct {
+		linear, encoded float32
+	}{
+		{0, 0},
+		{0.01, 0.045},
+		{0.018, 0.081248},
+		{0.5, 0.70552},
+		{1, 1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := LinearToRec709(tt.linear); Abs(got-tt.encoded) > 1e-4 {
+				t.Errorf("LinearToRec709(%v) = %v, want %v", tt.linear, got, tt.encoded)
+			}
+
+			if got := Rec709ToLinear(tt.encoded); Abs(got-tt.linear) > 1e-4 {
+				t.Errorf("Rec709ToLinear(%v) = %v, want %v", tt.encoded, got, tt.linear)
+			}
+		})
+	}
+}
+
+func TestVec4f_EncodeSRGB(t *testing.T) {
+	v := NewRGBA(0.5, 1, 0, 0.5)
+	v.EncodeSRGB()
+	want := NewRGBA(0.73536, 1, 0, 0.5)
+	if !v.Equals(&want) {
+		t.Errorf("EncodeSRGB() = %v, want %v", v, want)
+	}
+
+	v.DecodeSRGB()
+	want = NewRGBA(0.5, 1, 0, 0.5)
+	if !v.Equals(&want) {
+		t.Errorf("DecodeSRGB() = %v, want %v", v, want)
+	}
+
+	v.EncodeRec709()
+	want = NewRGBA(0.70552, 1, 0, 0.5)
+	if !v.Equals(&want) {
+		t.Errorf("EncodeRec709() = %v, want %v", v, want)
+	}
+
+	v.DecodeRec709()
+	want = NewRGBA(0.5, 1, 0, 0.5)
+	if !v.Equals(&want) {
+		t.Errorf("DecodeRec709() = %v, want %v", v, want)
+	}
+}
+
+func TestVec4f_ColorSpaces(t *testing.T) {
+	tests := []struct {
+		srgb, xyz, p3 Vec4f
+	}{
+		{NewRGB(0, 0, 0), NewRGB(0, 0, 0), NewRGB(0, 0, 0)},
+		{NewRGB(1, 1, 1), NewRGB(0.95046, 1, 1.08906), NewRGB(1, 1, 1)},
+		{NewRGB(1, 0, 0), NewRGB(0.4123908, 0.2126390, 0.0193308), NewRGB(0.82246, 0.03319, 0.01708)},
+		{NewRGB(0, 1, 0), NewRGB(0.3575843, 0.7151687, 0.1191948), NewRGB(0.17754, 0.96681, 0.07240)},
+		{NewRGBA(0, 0, 1, 0.5), NewRGBA(0.1804808, 0.0721923, 0.9505322, 0.5), NewRGBA(0, 0, 0.91052, 0.5)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := tt.srgb
+			v.SRGBToXYZ()
+			if !equalColor(&v, &tt.xyz) {
+				t.Errorf("SRGBToXYZ() = %v, want %v", v, tt.xyz)
+			}
+
+			v.XYZToDisplayP3()
+			if !equalColor(&v, &tt.p3) {
+				t.Errorf("XYZToDisplayP3() = %v, want %v", v, tt.p3)
+			}
+
+			v.DisplayP3ToXYZ()
+			if !equalColor(&v, &tt.xyz) {
+				t.Errorf("DisplayP3ToXYZ() = %v, want %v", v, tt.xyz)
+			}
+
+			v.XYZToSRGB()
+			if !equalColor(&v, &tt.srgb) {
+				t.Errorf("XYZToSRGB() = %v, want %v", v, tt.srgb)
+			}
+
+			v.SRGBToDisplayP3()
+			if !equalColor(&v, &tt.p3) {
+				t.Errorf("SRGBToDisplayP3() = %v, want %v", v, tt.p3)
+			}
+
+			v.DisplayP3ToSRGB()
+			if !equalColor(&v, &tt.srgb) {
+				t.Errorf("DisplayP3ToSRGB() = %v, want %v", v, tt.srgb)
+			}
+		})
+	}
+}
+
+func TestVec4f_XyY(t *testing.T) {
+	tests := []struct {
+		xyz, xyY Vec4f
+	}{
+		{NewRGB(0.95046, 1, 1.08906), NewRGB(D65x, D65y, 1)},
+		{NewRGB(0.5, 0.25, 0.25), NewRGB(0.5, 0.25, 0.25)},
+		{NewRGBA(0, 0, 0, 0.5), NewRGBA(D65x, D65y, 0, 0.5)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := tt.xyz
+			v.XYZToXyY()
+			if !equalColor(&v, &tt.xyY) {
+				t.Errorf("XYZToXyY() = %v, want %v", v, tt.xyY)
+			}
+
+			v.XyYToXYZ()
+			if !equalColor(&v, &tt.xyz) {
+				t.Errorf("XyYToXYZ() = %v, want %v", v, tt.xyz)
+			}
+		})
+	}
+}
+
+func TestVec4f_Premultiply(t *testing.T) {
+	tests := []struct {
+		straight, premultiplied Vec4f
+	}{
+		{NewRGBA(1, 0.5, 0.25, 1), NewRGBA(1, 0.5, 0.25, 1)},
+		{NewRGBA(1, 0.5, 0.25, 0.5), NewRGBA(0.5, 0.25, 0.125, 0.5)},
+		{NewRGBA(0, 0, 0, 0), NewRGBA(0, 0, 0, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := tt.straight
+			v.Premultiply()
+			if v != tt.premultiplied {
+				t.Errorf("Premultiply() = %v, want %v", v, tt.premultiplied)
+			}
+
+			v.Unpremultiply()
+			if v != tt.straight {
+				t.Errorf("Unpremultiply() = %v, want %v", v, tt.straight)
+			}
+		})
+	}
+
+	// the color of a transparent pixel is lost
+	v := NewRGBA(1, 1, 1, 0)
+	v.Unpremultiply()
+	if want := NewRGBA(0, 0, 0, 0); v != want {
+		t.Errorf("Unpremultiply() = %v, want %v", v, want)
+	}
+}
+
+// equalColor compares with a tolerance, which respects the precision of the published matrices.
+func equalColor(a, b *Vec4f) bool {
+	return Abs(a.X-b.X) < 1e-4 && Abs(a.Y-b.Y) < 1e-4 && Abs(a.Z-b.Z) < 1e-4 && a.W == b.W
+}
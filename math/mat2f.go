@@ -0,0 +1,37 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// A Mat2f is a row major 2x2 matrix. It is mostly used as the smallest submatrix
+// when calculating determinants of larger matrices.
+type Mat2f [2][2]float32
+
+// Equals compares each element using Equalf.
+func (m *Mat2f) Equals(o *Mat2f) bool {
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 2; c++ {
+			if !Equalf(m[r][c], o[r][c]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Determinant returns ad-bc.
+func (m *Mat2f) Determinant() float32 {
+	return m[0][0]*m[1][1] - m[0][1]*m[1][0]
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMat2f_Determinant(t *testing.T) {
+	tests := []struct {
+		m   Mat2f
+		res float32
+	}{
+		{Mat2f{{1, 5}, {-3, 2}}, 17},
+		{Mat2f{{1, 0}, {0, 1}}, 1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := tt.m.Determinant(); !Equalf(got, tt.res) {
+				t.Errorf("det(%v) = %v, want %v", tt.m, got, tt.res)
+			}
+		})
+	}
+}
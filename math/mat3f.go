@@ -0,0 +1,82 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// A Mat3f is a row major 3x3 matrix. Like Mat2f, it is only required for the
+// cofactor expansion of a Mat4f.
+type Mat3f [3][3]float32
+
+// Equals compares each element using Equalf.
+func (m *Mat3f) Equals(o *Mat3f) bool {
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			if !Equalf(m[r][c], o[r][c]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Submatrix returns a copy of the matrix without the given row and column.
+func (m *Mat3f) Submatrix(row, col int) Mat2f {
+	var res Mat2f
+	dr := 0
+	for r := 0; r < 3; r++ {
+		if r == row {
+			continue
+		}
+
+		dc := 0
+		for c := 0; c < 3; c++ {
+			if c == col {
+				continue
+			}
+
+			res[dr][dc] = m[r][c]
+			dc++
+		}
+
+		dr++
+	}
+
+	return res
+}
+
+// Minor is the determinant of the submatrix at the given row and column.
+func (m *Mat3f) Minor(row, col int) float32 {
+	sub := m.Submatrix(row, col)
+	return sub.Determinant()
+}
+
+// Cofactor is the minor, which has its sign flipped if row+col is odd.
+func (m *Mat3f) Cofactor(row, col int) float32 {
+	if (row+col)%2 == 1 {
+		return -m.Minor(row, col)
+	}
+
+	return m.Minor(row, col)
+}
+
+// Determinant calculates the determinant by expanding the first row.
+func (m *Mat3f) Determinant() float32 {
+	var det float32
+	for c := 0; c < 3; c++ {
+		det += m[0][c] * m.Cofactor(0, c)
+	}
+
+	return det
+}
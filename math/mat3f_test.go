@@ -0,0 +1,75 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMat3f_Submatrix(t *testing.T) {
+	m := Mat3f{
+		{1, 5, 0},
+		{-3, 2, 7},
+		{0, 6, -3},
+	}
+	want := Mat2f{
+		{-3, 2},
+		{0, 6},
+	}
+
+	got := m.Submatrix(0, 2)
+	if !got.Equals(&want) {
+		t.Errorf("Submatrix() = %v, want %v", got, want)
+	}
+}
+
+func TestMat3f_Cofactor(t *testing.T) {
+	m := Mat3f{
+		{3, 5, 0},
+		{2, -1, -7},
+		{6, -1, 5},
+	}
+	tests := []struct {
+		row, col     int
+		minor, cofac float32
+	}{
+		{0, 0, -12, -12},
+		{1, 0, 25, -25},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := m.Minor(tt.row, tt.col); !Equalf(got, tt.minor) {
+				t.Errorf("Minor() = %v, want %v", got, tt.minor)
+			}
+
+			if got := m.Cofactor(tt.row, tt.col); !Equalf(got, tt.cofac) {
+				t.Errorf("Cofactor() = %v, want %v", got, tt.cofac)
+			}
+		})
+	}
+}
+
+func TestMat3f_Determinant(t *testing.T) {
+	m := Mat3f{
+		{1, 2, 6},
+		{-5, 8, -4},
+		{2, 6, 4},
+	}
+
+	if got := m.Determinant(); !Equalf(got, -196) {
+		t.Errorf("Determinant() = %v, want %v", got, -196)
+	}
+}
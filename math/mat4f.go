@@ -0,0 +1,143 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// A Mat4f is a row major 4x4 matrix, so m[row][col] addresses an element.
+// Just like Vec4f, the methods use pointer receivers and modify the receiver
+// in place, so that a matrix is not copied around all the time.
+type Mat4f [4][4]float32
+
+// Identity returns the identity matrix, which does not change anything when multiplied.
+func Identity() Mat4f {
+	return Mat4f{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Equals compares each element using Equalf.
+func (m *Mat4f) Equals(o *Mat4f) bool {
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			if !Equalf(m[r][c], o[r][c]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Mul sets the receiver to the matrix product m * o. Note, that matrix multiplication
+// is not commutative.
+func (m *Mat4f) Mul(o *Mat4f) {
+	var res Mat4f
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			res[r][c] = m[r][0]*o[0][c] +
+				m[r][1]*o[1][c] +
+				m[r][2]*o[2][c] +
+				m[r][3]*o[3][c]
+		}
+	}
+
+	*m = res
+}
+
+// Transpose swaps rows and columns.
+func (m *Mat4f) Transpose() {
+	for r := 0; r < 4; r++ {
+		for c := r + 1; c < 4; c++ {
+			m[r][c], m[c][r] = m[c][r], m[r][c]
+		}
+	}
+}
+
+// Submatrix returns a copy of the matrix without the given row and column.
+func (m *Mat4f) Submatrix(row, col int) Mat3f {
+	var res Mat3f
+	dr := 0
+	for r := 0; r < 4; r++ {
+		if r == row {
+			continue
+		}
+
+		dc := 0
+		for c := 0; c < 4; c++ {
+			if c == col {
+				continue
+			}
+
+			res[dr][dc] = m[r][c]
+			dc++
+		}
+
+		dr++
+	}
+
+	return res
+}
+
+// Minor is the determinant of the submatrix at the given row and column.
+func (m *Mat4f) Minor(row, col int) float32 {
+	sub := m.Submatrix(row, col)
+	return sub.Determinant()
+}
+
+// Cofactor is the minor, which has its sign flipped if row+col is odd.
+func (m *Mat4f) Cofactor(row, col int) float32 {
+	if (row+col)%2 == 1 {
+		return -m.Minor(row, col)
+	}
+
+	return m.Minor(row, col)
+}
+
+// Determinant calculates the determinant by expanding the first row.
+func (m *Mat4f) Determinant() float32 {
+	var det float32
+	for c := 0; c < 4; c++ {
+		det += m[0][c] * m.Cofactor(0, c)
+	}
+
+	return det
+}
+
+// Invertible returns true, if the determinant is not zero.
+func (m *Mat4f) Invertible() bool {
+	return m.Determinant() != 0
+}
+
+// Invert sets the receiver to its inverse and returns true. If the matrix is not invertible,
+// the receiver is left untouched and false is returned.
+func (m *Mat4f) Invert() bool {
+	det := m.Determinant()
+	if det == 0 {
+		return false
+	}
+
+	var res Mat4f
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			// the transposition is done by swapping c and r
+			res[c][r] = m.Cofactor(r, c) / det
+		}
+	}
+
+	*m = res
+	return true
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMat4f_Equals(t *testing.T) {
+	tests := []struct {
+		a, b Mat4f
+		want bool
+	}{
+		{Identity(), Identity(), true},
+		{Identity(), Mat4f{}, false},
+		{Mat4f{{1, 2, 3, 4}}, Mat4f{{1, 2, 3, 4 + Epsilon*0.1}}, true},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := tt.a.Equals(&tt.b); got != tt.want {
+				t.Errorf("Equals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMat4f_Mul(t *testing.T) {
+	tests := []struct {
+		a, b Mat4f
+		res  Mat4f
+	}{
+		{
+			Mat4f{
+				{1, 2, 3, 4},
+				{5, 6, 7, 8},
+				{9, 8, 7, 6},
+				{5, 4, 3, 2},
+			},
+			Mat4f{
+				{-2, 1, 2, 3},
+				{3, 2, 1, -1},
+				{4, 3, 6, 5},
+				{1, 2, 7, 8},
+			},
+			Mat4f{
+				{20, 22, 50, 48},
+				{44, 54, 114, 108},
+				{40, 58, 110, 102},
+				{16, 26, 46, 42},
+			},
+		},
+		{
+			Mat4f{
+				{0, 1, 2, 4},
+				{1, 2, 4, 8},
+				{2, 4, 8, 16},
+				{4, 8, 16, 32},
+			},
+			Identity(),
+			Mat4f{
+				{0, 1, 2, 4},
+				{1, 2, 4, 8},
+				{2, 4, 8, 16},
+				{4, 8, 16, 32},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := tt.a
+			tmp.Mul(&tt.b)
+			if !tmp.Equals(&tt.res) {
+				t.Errorf("%v * %v = %v, want %v", tt.a, tt.b, tmp, tt.res)
+			}
+		})
+	}
+}
+
+func TestMat4f_Transpose(t *testing.T) {
+	m := Mat4f{
+		{0, 9, 3, 0},
+		{9, 8, 0, 8},
+		{1, 8, 5, 3},
+		{0, 0, 5, 8},
+	}
+	want := Mat4f{
+		{0, 9, 1, 0},
+		{9, 8, 8, 0},
+		{3, 0, 5, 5},
+		{0, 8, 3, 8},
+	}
+
+	m.Transpose()
+	if !m.Equals(&want) {
+		t.Errorf("Transpose() = %v, want %v", m, want)
+	}
+
+	id := Identity()
+	id.Transpose()
+	want = Identity()
+	if !id.Equals(&want) {
+		t.Errorf("Transpose() = %v, want %v", id, want)
+	}
+}
+
+func TestMat4f_Determinant(t *testing.T) {
+	m := Mat4f{
+		{-2, -8, 3, 5},
+		{-3, 1, 7, 3},
+		{1, 2, -9, 6},
+		{-6, 7, 7, -9},
+	}
+	tests := []struct {
+		col   int
+		cofac float32
+	}{
+		{0, 690},
+		{1, 447},
+		{2, 210},
+		{3, 51},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := m.Cofactor(0, tt.col); !Equalf(got, tt.cofac) {
+				t.Errorf("Cofactor() = %v, want %v", got, tt.cofac)
+			}
+		})
+	}
+
+	if got := m.Determinant(); !Equalf(got, -4071) {
+		t.Errorf("Determinant() = %v, want %v", got, -4071)
+	}
+}
+
+func TestMat4f_Invert(t *testing.T) {
+	tests := []struct {
+		m          Mat4f
+		invertible bool
+		res        Mat4f
+	}{
+		{
+			Mat4f{
+				{-5, 2, 6, -8},
+				{1, -5, 1, 8},
+				{7, 7, -6, -7},
+				{1, -3, 7, 4},
+			},
+			true,
+			Mat4f{
+				{0.21805, 0.45113, 0.24060, -0.04511},
+				{-0.80827, -1.45677, -0.44361, 0.52068},
+				{-0.07895, -0.22368, -0.05263, 0.19737},
+				{-0.52256, -0.81391, -0.30075, 0.30639},
+			},
+		},
+		{
+			Mat4f{
+				{-4, 2, -2, -3},
+				{9, 6, 2, 6},
+				{0, -5, 1, -5},
+				{0, 0, 0, 0},
+			},
+			false,
+			Mat4f{
+				{-4, 2, -2, -3},
+				{9, 6, 2, 6},
+				{0, -5, 1, -5},
+				{0, 0, 0, 0},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := tt.m
+			if got := tmp.Invertible(); got != tt.invertible {
+				t.Errorf("Invertible() = %v, want %v", got, tt.invertible)
+			}
+
+			if got := tmp.Invert(); got != tt.invertible {
+				t.Errorf("Invert() = %v, want %v", got, tt.invertible)
+			}
+
+			if !tmp.Equals(&tt.res) {
+				t.Errorf("inverse(%v) = %v, want %v", tt.m, tmp, tt.res)
+			}
+		})
+	}
+
+	// multiplying a product by the inverse of one factor must give back the other factor
+	a := Mat4f{
+		{3, -9, 7, 3},
+		{3, -8, 2, -9},
+		{-4, 4, 4, 1},
+		{-6, 5, -1, 1},
+	}
+	b := Mat4f{
+		{8, 2, 2, 2},
+		{3, -1, 7, 0},
+		{7, 0, 5, 4},
+		{6, -2, 0, 5},
+	}
+	c := a
+	c.Mul(&b)
+	b.Invert()
+	c.Mul(&b)
+	if !c.Equals(&a) {
+		t.Errorf("c * inverse(b) = %v, want %v", c, a)
+	}
+}
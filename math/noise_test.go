@@ -0,0 +1,48 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestNoise(t *testing.T) {
+	tests := []struct {
+		x, y, z float32
+		res     float32
+	}{
+		{0, 0, 0, 0},
+		{1, 2, 3, 0},
+		{-7, 12, 300, 0},
+		// reference value from the java implementation of improved noise
+		{3.14, 42, 7, 0.13691995},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := Noise(tt.x, tt.y, tt.z); !Equalf(got, tt.res) {
+				t.Errorf("Noise(%v, %v, %v) = %v, want %v", tt.x, tt.y, tt.z, got, tt.res)
+			}
+		})
+	}
+
+	for i := 0; i < 1000; i++ {
+		v := float32(i) * 0.137
+		n := Noise(v, v*0.5, -v)
+		if n < -1 || n > 1 {
+			t.Errorf("Noise(%v) = %v, want within [-1, 1]", v, n)
+		}
+	}
+}
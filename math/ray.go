@@ -0,0 +1,41 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// A Ray starts at its origin point and points into its direction vector.
+type Ray struct {
+	Origin    Vec4f // a point
+	Direction Vec4f // a vector, which is not necessarily normalized
+}
+
+// NewRay creates a ray from the given point and vector.
+func NewRay(origin, direction Vec4f) Ray {
+	return Ray{Origin: origin, Direction: direction}
+}
+
+// Position returns the point at the distance t along the ray.
+func (r *Ray) Position(t float32) Vec4f {
+	p := r.Direction
+	p.Mul(t)
+	p.Add(&r.Origin)
+	return p
+}
+
+// Transform applies the matrix to the origin and the direction. The direction is not
+// normalized afterwards, so that the distances t are still comparable in world space.
+func (r *Ray) Transform(m *Mat4f) {
+	r.Origin.Transform(m)
+	r.Direction.Transform(m)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRay_Position(t *testing.T) {
+	r := NewRay(NewPoint(2, 3, 4), NewVector(1, 0, 0))
+	tests := []struct {
+		t   float32
+		res Vec4f
+	}{
+		{0, NewPoint(2, 3, 4)},
+		{1, NewPoint(3, 3, 4)},
+		{-1, NewPoint(1, 3, 4)},
+		{2.5, NewPoint(4.5, 3, 4)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := r.Position(tt.t); !got.Equals(&tt.res) {
+				t.Errorf("Position(%v) = %v, want %v", tt.t, got, tt.res)
+			}
+		})
+	}
+}
+
+func TestRay_Transform(t *testing.T) {
+	tests := []struct {
+		m                 Mat4f
+		origin, direction Vec4f
+	}{
+		{Translation(3, 4, 5), NewPoint(4, 6, 8), NewVector(0, 1, 0)},
+		{Scaling(2, 3, 4), NewPoint(2, 6, 12), NewVector(0, 3, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			r := NewRay(NewPoint(1, 2, 3), NewVector(0, 1, 0))
+			r.Transform(&tt.m)
+			if !r.Origin.Equals(&tt.origin) {
+				t.Errorf("origin = %v, want %v", r.Origin, tt.origin)
+			}
+
+			if !r.Direction.Equals(&tt.direction) {
+				t.Errorf("direction = %v, want %v", r.Direction, tt.direction)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+// Translation returns a matrix which moves a point. Vectors are not affected, because
+// their W component is 0 and therefore the last column is ignored.
+func Translation(x, y, z float32) Mat4f {
+	return Mat4f{
+		{1, 0, 0, x},
+		{0, 1, 0, y},
+		{0, 0, 1, z},
+		{0, 0, 0, 1},
+	}
+}
+
+// Scaling returns a matrix which scales points and vectors. Negative values reflect.
+func Scaling(x, y, z float32) Mat4f {
+	return Mat4f{
+		{x, 0, 0, 0},
+		{0, y, 0, 0},
+		{0, 0, z, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotationX returns a matrix which rotates around the x axis by the given radians. Due to our
+// left-handed coordinate system, the rotation is clockwise when looking along the axis towards the origin.
+func RotationX(r float32) Mat4f {
+	sin, cos := Sin(r), Cos(r)
+	return Mat4f{
+		{1, 0, 0, 0},
+		{0, cos, -sin, 0},
+		{0, sin, cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotationY returns a matrix which rotates around the y axis by the given radians.
+func RotationY(r float32) Mat4f {
+	sin, cos := Sin(r), Cos(r)
+	return Mat4f{
+		{cos, 0, sin, 0},
+		{0, 1, 0, 0},
+		{-sin, 0, cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotationZ returns a matrix which rotates around the z axis by the given radians.
+func RotationZ(r float32) Mat4f {
+	sin, cos := Sin(r), Cos(r)
+	return Mat4f{
+		{cos, -sin, 0, 0},
+		{sin, cos, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Shearing returns a matrix which moves each component in proportion to the other two components,
+// e.g. xy moves x in proportion to y.
+func Shearing(xy, xz, yx, yz, zx, zy float32) Mat4f {
+	return Mat4f{
+		{1, xy, xz, 0},
+		{yx, 1, yz, 0},
+		{zx, zy, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// The following methods provide a fluent api, so that the transformations are applied in the
+// order of writing, e.g.
+//   Identity().RotateX(Pi/2).Scale(5, 5, 5).Translate(10, 5, 7)
+// first rotates, then scales and finally translates. Each call prepends the transformation to
+// the receiver, because the matrix closest to the vector is applied first. These methods use
+// value receivers intentionally, so that they can be chained on returned values.
+
+// Translate returns a copy of m, which is translated afterwards.
+func (m Mat4f) Translate(x, y, z float32) Mat4f {
+	return m.then(Translation(x, y, z))
+}
+
+// Scale returns a copy of m, which is scaled afterwards.
+func (m Mat4f) Scale(x, y, z float32) Mat4f {
+	return m.then(Scaling(x, y, z))
+}
+
+// RotateX returns a copy of m, which is rotated around the x axis afterwards.
+func (m Mat4f) RotateX(r float32) Mat4f {
+	return m.then(RotationX(r))
+}
+
+// RotateY returns a copy of m, which is rotated around the y axis afterwards.
+func (m Mat4f) RotateY(r float32) Mat4f {
+	return m.then(RotationY(r))
+}
+
+// RotateZ returns a copy of m, which is rotated around the z axis afterwards.
+func (m Mat4f) RotateZ(r float32) Mat4f {
+	return m.then(RotationZ(r))
+}
+
+// Shear returns a copy of m, which is sheared afterwards.
+func (m Mat4f) Shear(xy, xz, yx, yz, zx, zy float32) Mat4f {
+	return m.then(Shearing(xy, xz, yx, yz, zx, zy))
+}
+
+// then returns t * m.
+func (m Mat4f) then(t Mat4f) Mat4f {
+	t.Mul(&m)
+	return t
+}
+
+// ViewTransform returns a matrix which orients the world relative to the eye. The eye is at the point
+// from and looks at the point to, while up is a vector which roughly points upwards.
+func ViewTransform(from, to, up Vec4f) Mat4f {
+	forward := to
+	forward.Sub(&from)
+	forward.Normalize()
+
+	upn := up
+	upn.Normalize()
+
+	left := forward
+	left.Cross(&upn)
+
+	// up is only approximate, so calculate the true up vector
+	trueUp := left
+	trueUp.Cross(&forward)
+
+	orientation := Mat4f{
+		{left.X, left.Y, left.Z, 0},
+		{trueUp.X, trueUp.Y, trueUp.Z, 0},
+		{-forward.X, -forward.Y, -forward.Z, 0},
+		{0, 0, 0, 1},
+	}
+
+	translation := Translation(-from.X, -from.Y, -from.Z)
+	orientation.Mul(&translation)
+
+	return orientation
+}
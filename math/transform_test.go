@@ -0,0 +1,130 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package math
+
+import (
+	"strconv"
+	"testing"
+)
+
+func inverse(m Mat4f) Mat4f {
+	m.Invert()
+	return m
+}
+
+func TestTransformations(t *testing.T) {
+	sqrt2 := Sqrt(2) / 2
+	tests := []struct {
+		m   Mat4f
+		v   Vec4f
+		res Vec4f
+	}{
+		{Translation(5, -3, 2), NewPoint(-3, 4, 5), NewPoint(2, 1, 7)},
+		{inverse(Translation(5, -3, 2)), NewPoint(-3, 4, 5), NewPoint(-8, 7, 3)},
+		{Translation(5, -3, 2), NewVector(-3, 4, 5), NewVector(-3, 4, 5)},
+		{Scaling(2, 3, 4), NewPoint(-4, 6, 8), NewPoint(-8, 18, 32)},
+		{Scaling(2, 3, 4), NewVector(-4, 6, 8), NewVector(-8, 18, 32)},
+		{inverse(Scaling(2, 3, 4)), NewVector(-4, 6, 8), NewVector(-2, 2, 2)},
+		{Scaling(-1, 1, 1), NewPoint(2, 3, 4), NewPoint(-2, 3, 4)},
+		{RotationX(Pi / 4), NewPoint(0, 1, 0), NewPoint(0, sqrt2, sqrt2)},
+		{RotationX(Pi / 2), NewPoint(0, 1, 0), NewPoint(0, 0, 1)},
+		{inverse(RotationX(Pi / 4)), NewPoint(0, 1, 0), NewPoint(0, sqrt2, -sqrt2)},
+		{RotationY(Pi / 4), NewPoint(0, 0, 1), NewPoint(sqrt2, 0, sqrt2)},
+		{RotationY(Pi / 2), NewPoint(0, 0, 1), NewPoint(1, 0, 0)},
+		{RotationZ(Pi / 4), NewPoint(0, 1, 0), NewPoint(-sqrt2, sqrt2, 0)},
+		{RotationZ(Pi / 2), NewPoint(0, 1, 0), NewPoint(-1, 0, 0)},
+		{Shearing(1, 0, 0, 0, 0, 0), NewPoint(2, 3, 4), NewPoint(5, 3, 4)},
+		{Shearing(0, 1, 0, 0, 0, 0), NewPoint(2, 3, 4), NewPoint(6, 3, 4)},
+		{Shearing(0, 0, 1, 0, 0, 0), NewPoint(2, 3, 4), NewPoint(2, 5, 4)},
+		{Shearing(0, 0, 0, 1, 0, 0), NewPoint(2, 3, 4), NewPoint(2, 7, 4)},
+		{Shearing(0, 0, 0, 0, 1, 0), NewPoint(2, 3, 4), NewPoint(2, 3, 6)},
+		{Shearing(0, 0, 0, 0, 0, 1), NewPoint(2, 3, 4), NewPoint(2, 3, 7)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := tt.v
+			tmp.Transform(&tt.m)
+			if !tmp.Equals(&tt.res) {
+				t.Errorf("%v * %v = %v, want %v", tt.m, tt.v, tmp, tt.res)
+			}
+		})
+	}
+}
+
+func TestMat4f_Fluent(t *testing.T) {
+	p := NewPoint(1, 0, 1)
+	a := RotationX(Pi / 2)
+	b := Scaling(5, 5, 5)
+	c := Translation(10, 5, 7)
+
+	// applied in sequence
+	seq := p
+	seq.Transform(&a)
+	seq.Transform(&b)
+	seq.Transform(&c)
+
+	// chained in reverse order
+	chained := c
+	chained.Mul(&b)
+	chained.Mul(&a)
+
+	fluent := Identity().RotateX(Pi/2).Scale(5, 5, 5).Translate(10, 5, 7)
+	if !fluent.Equals(&chained) {
+		t.Errorf("fluent = %v, want %v", fluent, chained)
+	}
+
+	want := NewPoint(15, 0, 7)
+	if !seq.Equals(&want) {
+		t.Errorf("sequence = %v, want %v", seq, want)
+	}
+
+	p.Transform(&fluent)
+	if !p.Equals(&want) {
+		t.Errorf("fluent * p = %v, want %v", p, want)
+	}
+}
+
+func TestViewTransform(t *testing.T) {
+	tests := []struct {
+		from, to, up Vec4f
+		res          Mat4f
+	}{
+		{NewPoint(0, 0, 0), NewPoint(0, 0, -1), NewVector(0, 1, 0), Identity()},
+		{NewPoint(0, 0, 0), NewPoint(0, 0, 1), NewVector(0, 1, 0), Scaling(-1, 1, -1)},
+		{NewPoint(0, 0, 8), NewPoint(0, 0, 0), NewVector(0, 1, 0), Translation(0, 0, -8)},
+		{
+			NewPoint(1, 3, 2), NewPoint(4, -2, 8), NewVector(1, 1, 0),
+			Mat4f{
+				{-0.50709, 0.50709, 0.67612, -2.36643},
+				{0.76772, 0.60609, 0.12122, -2.82843},
+				{-0.35857, 0.59761, -0.71714, 0.00000},
+				{0.00000, 0.00000, 0.00000, 1.00000},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := ViewTransform(tt.from, tt.to, tt.up)
+			for r := 0; r < 4; r++ {
+				for c := 0; c < 4; c++ {
+					// the expected values are rounded to 5 decimal places
+					if Abs(got[r][c]-tt.res[r][c]) > 0.0001 {
+						t.Fatalf("ViewTransform() = %v, want %v", got, tt.res)
+					}
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,9 @@ import "math"
 // Epsilon is the margin by which floats are treated equal.
 const Epsilon = 0.00001
 
+// Pi is re-exported from the stdlib, so that importers of this package do not need to rename imports.
+const Pi = math.Pi
+
 // Equalf returns true if the difference between the given two floats is smaller than epsilon.
 // Take a look at Knuths book, because this is not necessarily correct and only works
 // reasonable for values near zero.
@@ -41,3 +44,43 @@ func Abs(x float32) float32 {
 func Sqrt(x float32) float32 {
 	return float32(math.Sqrt(float64(x)))
 }
+
+// Sin is just like math.Sin but with float32.
+func Sin(x float32) float32 {
+	return float32(math.Sin(float64(x)))
+}
+
+// Cos is just like math.Cos but with float32.
+func Cos(x float32) float32 {
+	return float32(math.Cos(float64(x)))
+}
+
+// Pow is just like math.Pow but with float32.
+func Pow(x, y float32) float32 {
+	return float32(math.Pow(float64(x), float64(y)))
+}
+
+// Tan is just like math.Tan but with float32.
+func Tan(x float32) float32 {
+	return float32(math.Tan(float64(x)))
+}
+
+// Floor is just like math.Floor but with float32.
+func Floor(x float32) float32 {
+	return float32(math.Floor(float64(x)))
+}
+
+// Inf is just like math.Inf but returns a float32.
+func Inf(sign int) float32 {
+	return float32(math.Inf(sign))
+}
+
+// Min is just like math.Min but with float32.
+func Min(x, y float32) float32 {
+	return float32(math.Min(float64(x), float64(y)))
+}
+
+// Max is just like math.Max but with float32.
+func Max(x, y float32) float32 {
+	return float32(math.Max(float64(x), float64(y)))
+}
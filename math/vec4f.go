@@ -139,6 +139,24 @@ func (v *Vec4f) Cross(o *Vec4f) {
 	)
 }
 
+// Reflect mirrors the receiver around the given normal vector, like a ball bouncing off a wall.
+func (v *Vec4f) Reflect(normal *Vec4f) {
+	n := *normal
+	n.Mul(2 * v.Dot(normal))
+	v.Sub(&n)
+}
+
+// Transform sets the receiver to the product of the given matrix and the receiver, which is
+// treated as a column vector.
+func (v *Vec4f) Transform(m *Mat4f) {
+	*v = Vec4f{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z + m[0][3]*v.W,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z + m[1][3]*v.W,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z + m[2][3]*v.W,
+		W: m[3][0]*v.X + m[3][1]*v.Y + m[3][2]*v.Z + m[3][3]*v.W,
+	}
+}
+
 // Saturate clamps all components range into 0 and 1.
 func (v *Vec4f) Saturate() {
 	if v.X < 0 {
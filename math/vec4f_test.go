@@ -291,3 +291,54 @@ func TestVec4f_MulVec(t *testing.T) {
 		})
 	}
 }
+
+func TestVec4f_Transform(t *testing.T) {
+	tests := []struct {
+		a   Vec4f
+		m   Mat4f
+		res Vec4f
+	}{
+		{
+			NewPoint(1, 2, 3),
+			Mat4f{
+				{1, 2, 3, 4},
+				{2, 4, 4, 2},
+				{8, 6, 4, 1},
+				{0, 0, 0, 1},
+			},
+			NewPoint(18, 24, 33),
+		},
+		{NewVector(1, 2, 3), Identity(), NewVector(1, 2, 3)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := tt.a
+			tmp.Transform(&tt.m)
+
+			if !tmp.Equals(&tt.res) {
+				t.Errorf("%v * %v = %v, want %v", tt.m, tt.a, tmp, tt.res)
+			}
+		})
+	}
+}
+
+func TestVec4f_Reflect(t *testing.T) {
+	sqrt2 := Sqrt(2) / 2
+	tests := []struct {
+		a, n Vec4f
+		res  Vec4f
+	}{
+		{NewVector(1, -1, 0), NewVector(0, 1, 0), NewVector(1, 1, 0)},
+		{NewVector(0, -1, 0), NewVector(sqrt2, sqrt2, 0), NewVector(1, 0, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tmp := tt.a
+			tmp.Reflect(&tt.n)
+
+			if !tmp.Equals(&tt.res) {
+				t.Errorf("reflect(%v, %v) = %v, want %v", tt.a, tt.n, tmp, tt.res)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/torbenschinke/rtc/math"
+	"github.com/torbenschinke/rtc/tracer"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A ParseError describes a malformed statement and the line where it occurred.
+type ParseError struct {
+	Line int // 1-based line number
+	Msg  string
+	Err  error // the cause or nil
+}
+
+func (e *ParseError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("obj: line %d: %s: %v", e.Line, e.Msg, e.Err)
+	}
+
+	return fmt.Sprintf("obj: line %d: %s", e.Line, e.Msg)
+}
+
+// Unwrap returns the cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// A Model contains the parsed data. All indices in the file are 1-based, but the slices are 0-based.
+type Model struct {
+	Vertices        []math.Vec4f    // points from v statements, without the optional weight
+	Normals         []math.Vec4f    // vectors from vn statements
+	TextureVertices []math.Vec4f    // u, v and w in x, y and z from vt statements
+	DefaultGroup    *tracer.Group   // contains all faces, which have been defined before the first g statement
+	Groups          []*tracer.Group // the named groups in the order of their first appearance
+	GroupNames      []string        // the names of the Groups at the same index
+	Ignored         []int           // line numbers of unsupported statements
+}
+
+// Group returns a new group, which contains the default group and all named groups. Because a shape
+// can only have a single parent, this must only be called once.
+func (m *Model) Group() *tracer.Group {
+	g := tracer.NewGroup()
+	g.AddChild(m.DefaultGroup)
+	for _, named := range m.Groups {
+		g.AddChild(named)
+	}
+
+	return g
+}
+
+// NamedGroup returns the group with the given name or nil.
+func (m *Model) NamedGroup(name string) *tracer.Group {
+	for i, n := range m.GroupNames {
+		if n == name {
+			return m.Groups[i]
+		}
+	}
+
+	return nil
+}
+
+// Parse reads an OBJ file from the reader. Polygons are triangulated as a fan around their first vertex.
+// Faces whose vertices all reference a normal become tracer.SmoothTriangle, otherwise tracer.Triangle.
+// Unsupported statements are skipped and recorded in Model.Ignored, but malformed supported statements
+// fail with a *ParseError.
+func Parse(r io.Reader) (*Model, error) {
+	p := &parser{
+		model: &Model{DefaultGroup: tracer.NewGroup()},
+	}
+	p.current = p.model.DefaultGroup
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		if err := p.parseLine(scanner.Text()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.model, nil
+}
+
+type parser struct {
+	model   *Model
+	current *tracer.Group
+	line    int
+}
+
+func (p *parser) errorf(cause error, format string, args ...interface{}) error {
+	return &ParseError{Line: p.line, Msg: fmt.Sprintf(format, args...), Err: cause}
+}
+
+func (p *parser) parseLine(text string) error {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+
+	args := fields[1:]
+	switch fields[0] {
+	case "v":
+		// the optional 4th value is the weight of rational curves, which does not affect a point
+		v, err := p.parseFloats(args, 3, 4)
+		if err != nil {
+			return err
+		}
+
+		p.model.Vertices = append(p.model.Vertices, math.NewPoint(v[0], v[1], v[2]))
+	case "vn":
+		v, err := p.parseFloats(args, 3, 3)
+		if err != nil {
+			return err
+		}
+
+		p.model.Normals = append(p.model.Normals, math.NewVector(v[0], v[1], v[2]))
+	case "vt":
+		v, err := p.parseFloats(args, 1, 3)
+		if err != nil {
+			return err
+		}
+
+		var uvw [3]float32
+		copy(uvw[:], v)
+		p.model.TextureVertices = append(p.model.TextureVertices, math.NewVector(uvw[0], uvw[1], uvw[2]))
+	case "f":
+		return p.parseFace(args)
+	case "g":
+		if len(args) == 0 {
+			return p.errorf(nil, "missing group name")
+		}
+
+		p.selectGroup(strings.Join(args, " "))
+	default:
+		p.model.Ignored = append(p.model.Ignored, p.line)
+	}
+
+	return nil
+}
+
+func (p *parser) parseFloats(args []string, min, max int) ([]float32, error) {
+	if len(args) < min || len(args) > max {
+		return nil, p.errorf(nil, "expected %d to %d values but found %d", min, max, len(args))
+	}
+
+	res := make([]float32, len(args))
+	for i, a := range args {
+		f, err := strconv.ParseFloat(a, 32)
+		if err != nil {
+			return nil, p.errorf(err, "invalid number '%s'", a)
+		}
+
+		res[i] = float32(f)
+	}
+
+	return res, nil
+}
+
+// faceVertex contains the resolved 0-based indices or -1 if not defined.
+type faceVertex struct {
+	vertex, texture, normal int
+}
+
+func (p *parser) parseFace(args []string) error {
+	if len(args) < 3 {
+		return p.errorf(nil, "a face requires at least 3 vertices but found %d", len(args))
+	}
+
+	vertices := make([]faceVertex, len(args))
+	smooth := true
+	for i, a := range args {
+		fv, err := p.parseFaceVertex(a)
+		if err != nil {
+			return err
+		}
+
+		if fv.normal < 0 {
+			smooth = false
+		}
+
+		vertices[i] = fv
+	}
+
+	// fan triangulation, which is only correct for convex polygons
+	m := p.model
+	for i := 1; i < len(vertices)-1; i++ {
+		a, b, c := vertices[0], vertices[i], vertices[i+1]
+		if smooth {
+			p.current.AddChild(tracer.NewSmoothTriangle(
+				m.Vertices[a.vertex], m.Vertices[b.vertex], m.Vertices[c.vertex],
+				m.Normals[a.normal], m.Normals[b.normal], m.Normals[c.normal],
+			))
+		} else {
+			p.current.AddChild(tracer.NewTriangle(m.Vertices[a.vertex], m.Vertices[b.vertex], m.Vertices[c.vertex]))
+		}
+	}
+
+	return nil
+}
+
+// parseFaceVertex parses the forms v, v/vt, v//vn and v/vt/vn.
+func (p *parser) parseFaceVertex(s string) (faceVertex, error) {
+	fv := faceVertex{vertex: -1, texture: -1, normal: -1}
+	parts := strings.Split(s, "/")
+	if len(parts) > 3 {
+		return fv, p.errorf(nil, "invalid face vertex '%s'", s)
+	}
+
+	var err error
+	fv.vertex, err = p.parseIndex(parts[0], len(p.model.Vertices))
+	if err != nil {
+		return fv, err
+	}
+
+	if len(parts) > 1 && parts[1] != "" {
+		fv.texture, err = p.parseIndex(parts[1], len(p.model.TextureVertices))
+		if err != nil {
+			return fv, err
+		}
+	}
+
+	if len(parts) > 2 && parts[2] != "" {
+		fv.normal, err = p.parseIndex(parts[2], len(p.model.Normals))
+		if err != nil {
+			return fv, err
+		}
+	}
+
+	return fv, nil
+}
+
+// parseIndex converts the 1-based or negative relative index into a 0-based index.
+func (p *parser) parseIndex(s string, count int) (int, error) {
+	idx, err := strconv.Atoi(s)
+	if err != nil {
+		return -1, p.errorf(err, "invalid index '%s'", s)
+	}
+
+	if idx < 0 {
+		// relative to the end of the list, so -1 is the last element
+		idx = count + idx + 1
+	}
+
+	if idx < 1 || idx > count {
+		return -1, p.errorf(nil, "index %s out of range [1, %d]", s, count)
+	}
+
+	return idx - 1, nil
+}
+
+func (p *parser) selectGroup(name string) {
+	if g := p.model.NamedGroup(name); g != nil {
+		p.current = g
+		return
+	}
+
+	g := tracer.NewGroup()
+	p.model.Groups = append(p.model.Groups, g)
+	p.model.GroupNames = append(p.model.GroupNames, name)
+	p.current = g
+}
@@ -0,0 +1,287 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package obj
+
+import (
+	"errors"
+	"github.com/torbenschinke/rtc/math"
+	"github.com/torbenschinke/rtc/tracer"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, text string) *Model {
+	t.Helper()
+	m, err := Parse(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func triangle(t *testing.T, s tracer.Shape) *tracer.Triangle {
+	t.Helper()
+	tri, ok := s.(*tracer.Triangle)
+	if !ok {
+		t.Fatalf("shape is %T, want *tracer.Triangle", s)
+	}
+
+	return tri
+}
+
+func TestParse_Ignored(t *testing.T) {
+	m := parse(t, `There was a young lady named Bright
+who traveled much faster than light.
+She set out one day
+in a relative way,
+and came back the previous night.`)
+
+	if len(m.Ignored) != 5 {
+		t.Errorf("len(Ignored) = %v, want 5", len(m.Ignored))
+	}
+}
+
+func TestParse_Vertices(t *testing.T) {
+	m := parse(t, `
+# a comment
+v -1 1 0
+v -1.0000 0.5000 0.0000
+v 1 0 0
+v 1 1 0
+v 1 2 3 0
+v 1 2 3 0.5`)
+
+	// the weight is ignored, so the vertices are always points
+	want := []math.Vec4f{
+		math.NewPoint(-1, 1, 0),
+		math.NewPoint(-1, 0.5, 0),
+		math.NewPoint(1, 0, 0),
+		math.NewPoint(1, 1, 0),
+		math.NewPoint(1, 2, 3),
+		math.NewPoint(1, 2, 3),
+	}
+
+	if len(m.Vertices) != len(want) {
+		t.Fatalf("len(Vertices) = %v, want %v", len(m.Vertices), len(want))
+	}
+
+	for i := range want {
+		if !m.Vertices[i].Equals(&want[i]) {
+			t.Errorf("Vertices[%d] = %v, want %v", i, m.Vertices[i], want[i])
+		}
+	}
+
+	if len(m.Ignored) != 0 {
+		t.Errorf("Ignored = %v, want none", m.Ignored)
+	}
+}
+
+func TestParse_Triangles(t *testing.T) {
+	m := parse(t, `
+v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+
+f 1 2 3
+f 1 3 4`)
+
+	children := m.DefaultGroup.Children()
+	if len(children) != 2 {
+		t.Fatalf("len(Children()) = %v, want 2", len(children))
+	}
+
+	tests := []struct {
+		tri        *tracer.Triangle
+		p1, p2, p3 math.Vec4f
+	}{
+		{triangle(t, children[0]), m.Vertices[0], m.Vertices[1], m.Vertices[2]},
+		{triangle(t, children[1]), m.Vertices[0], m.Vertices[2], m.Vertices[3]},
+	}
+
+	for i, tt := range tests {
+		if !tt.tri.P1.Equals(&tt.p1) || !tt.tri.P2.Equals(&tt.p2) || !tt.tri.P3.Equals(&tt.p3) {
+			t.Errorf("%d: triangle = %v %v %v, want %v %v %v", i, tt.tri.P1, tt.tri.P2, tt.tri.P3, tt.p1, tt.p2, tt.p3)
+		}
+
+		if tt.tri.Parent() != m.DefaultGroup {
+			t.Errorf("%d: Parent() = %v, want %v", i, tt.tri.Parent(), m.DefaultGroup)
+		}
+	}
+}
+
+func TestParse_Polygon(t *testing.T) {
+	m := parse(t, `
+v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+v 0 2 0
+
+f 1 2 3 4 5`)
+
+	children := m.DefaultGroup.Children()
+	if len(children) != 3 {
+		t.Fatalf("len(Children()) = %v, want 3", len(children))
+	}
+
+	for i, child := range children {
+		tri := triangle(t, child)
+		p2, p3 := m.Vertices[i+1], m.Vertices[i+2]
+		if !tri.P1.Equals(&m.Vertices[0]) || !tri.P2.Equals(&p2) || !tri.P3.Equals(&p3) {
+			t.Errorf("%d: triangle = %v %v %v, want %v %v %v", i, tri.P1, tri.P2, tri.P3, m.Vertices[0], p2, p3)
+		}
+	}
+}
+
+func TestParse_Groups(t *testing.T) {
+	m := parse(t, `
+v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+
+g FirstGroup
+f 1 2 3
+g SecondGroup
+f 1 3 4
+g FirstGroup
+f -4 -3 -2`)
+
+	if len(m.DefaultGroup.Children()) != 0 {
+		t.Errorf("len(DefaultGroup.Children()) = %v, want 0", len(m.DefaultGroup.Children()))
+	}
+
+	first := m.NamedGroup("FirstGroup")
+	second := m.NamedGroup("SecondGroup")
+	if first == nil || second == nil || m.NamedGroup("ThirdGroup") != nil {
+		t.Fatalf("GroupNames = %v", m.GroupNames)
+	}
+
+	if len(first.Children()) != 2 {
+		t.Errorf("len(FirstGroup.Children()) = %v, want 2", len(first.Children()))
+	}
+
+	// the relative indices reference the same vertices as 1 2 3
+	tri := triangle(t, first.Children()[1])
+	if !tri.P1.Equals(&m.Vertices[0]) || !tri.P3.Equals(&m.Vertices[2]) {
+		t.Errorf("triangle = %v %v %v", tri.P1, tri.P2, tri.P3)
+	}
+
+	tri = triangle(t, second.Children()[0])
+	if !tri.P3.Equals(&m.Vertices[3]) {
+		t.Errorf("P3 = %v, want %v", tri.P3, m.Vertices[3])
+	}
+
+	g := m.Group()
+	children := g.Children()
+	if len(children) != 3 || children[0] != m.DefaultGroup || children[1] != first || children[2] != second {
+		t.Errorf("Group().Children() = %v", children)
+	}
+
+	if first.Parent() != g {
+		t.Errorf("Parent() = %v, want %v", first.Parent(), g)
+	}
+}
+
+func TestParse_Normals(t *testing.T) {
+	m := parse(t, `
+v 0 1 0
+v -1 0 0
+v 1 0 0
+
+vn -1 0 0
+vn 1 0 0
+vn 0 1 0
+
+vt 0.5 0.25
+
+f 1//3 2//1 3//2
+f 1/1/3 2/1/1 3/1/2
+f 1/1 2/1 3/1`)
+
+	wantNormals := []math.Vec4f{
+		math.NewVector(-1, 0, 0),
+		math.NewVector(1, 0, 0),
+		math.NewVector(0, 1, 0),
+	}
+
+	for i := range wantNormals {
+		if !m.Normals[i].Equals(&wantNormals[i]) {
+			t.Errorf("Normals[%d] = %v, want %v", i, m.Normals[i], wantNormals[i])
+		}
+	}
+
+	wantTexture := math.NewVector(0.5, 0.25, 0)
+	if len(m.TextureVertices) != 1 || !m.TextureVertices[0].Equals(&wantTexture) {
+		t.Errorf("TextureVertices = %v, want [%v]", m.TextureVertices, wantTexture)
+	}
+
+	children := m.DefaultGroup.Children()
+	if len(children) != 3 {
+		t.Fatalf("len(Children()) = %v, want 3", len(children))
+	}
+
+	for i, child := range children[:2] {
+		tri, ok := child.(*tracer.SmoothTriangle)
+		if !ok {
+			t.Fatalf("%d: shape is %T, want *tracer.SmoothTriangle", i, child)
+		}
+
+		if !tri.P1.Equals(&m.Vertices[0]) || !tri.P2.Equals(&m.Vertices[1]) || !tri.P3.Equals(&m.Vertices[2]) {
+			t.Errorf("%d: triangle = %v %v %v", i, tri.P1, tri.P2, tri.P3)
+		}
+
+		if !tri.N1.Equals(&m.Normals[2]) || !tri.N2.Equals(&m.Normals[0]) || !tri.N3.Equals(&m.Normals[1]) {
+			t.Errorf("%d: normals = %v %v %v", i, tri.N1, tri.N2, tri.N3)
+		}
+	}
+
+	// without normals, the face is flat
+	triangle(t, children[2])
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"invalid number", "v 1 2 x", 1},
+		{"missing component", "v 1 2", 1},
+		{"too many components", "vn 1 2 3 4", 1},
+		{"index out of range", "v 1 2 3\nv 1 2 4\nv 2 2 3\nf 1 2 4", 4},
+		{"zero index", "v 1 2 3\nv 1 2 4\nv 2 2 3\nf 0 1 2", 4},
+		{"missing normal", "v 1 2 3\nv 1 2 4\nv 2 2 3\n\nf 1//1 2//1 3//1", 5},
+		{"invalid index", "v 1 2 3\nv 1 2 4\nv 2 2 3\nf 1 2 a", 4},
+		{"degenerated face", "v 1 2 3\nv 1 2 4\nf 1 2", 3},
+		{"missing group name", "g", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.text))
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *ParseError", err)
+			}
+
+			if perr.Line != tt.line {
+				t.Errorf("Line = %v, want %v", perr.Line, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"sort"
+)
+
+const (
+	bvhBuckets       = 12    // number of bins, in which the split candidates of a node are evaluated
+	bvhMaxLeafSize   = 4     // nodes with more shapes are always split
+	bvhTraversalCost = 0.125 // the cost to visit a node, relative to the cost of intersecting a shape
+)
+
+// bvhNode is a node of the flattened hierarchy. The first child of an interior node is always the
+// next node in the array, so only the index of the second child is stored. The node occupies 32 bytes,
+// so that two nodes fit into a cache line.
+type bvhNode struct {
+	min, max [3]float32
+	offset   int32 // a leaf refers to its first shape, an interior node to its second child
+	count    int32 // the number of shapes in a leaf or 0 for an interior node
+}
+
+// intersects performs the slab test with the reciprocal ray direction. A NaN, which results from
+// a ray in the plane of a slab (0 * Inf), is ignored by the comparisons, so that such a ray counts as inside.
+func (n *bvhNode) intersects(origin, invDir *[3]float32) bool {
+	tmin := math.Inf(-1)
+	tmax := math.Inf(1)
+	for a := 0; a < 3; a++ {
+		t0 := (n.min[a] - origin[a]) * invDir[a]
+		t1 := (n.max[a] - origin[a]) * invDir[a]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+
+		if t0 > tmin {
+			tmin = t0
+		}
+
+		if t1 < tmax {
+			tmax = t1
+		}
+
+		if tmin > tmax {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bvhPrimitive holds the precomputed parent space bounds of a shape during the build.
+type bvhPrimitive struct {
+	shape    Shape
+	bounds   math.BoundingBox
+	centroid [3]float32
+}
+
+// A BVH is a container like a Group, which organizes its shapes in a bounding volume hierarchy. In contrast
+// to Group.Divide, the hierarchy is built by the surface area heuristic (SAH) from binned split candidates
+// and stored as a flat array of nodes in depth first order, so that the traversal does not chase pointers.
+// Shapes with infinite bounds, like planes, are not part of the hierarchy and are always intersected.
+//
+// The hierarchy is rebuilt, whenever a shape within the BVH is transformed. Other modifications of a shape,
+// which change its bounds, must be done before it is added.
+type BVH struct {
+	baseShape
+	children  []Shape // in the order of NewBVH
+	shapes    []Shape // the bounded children in the order of the leaves
+	unbounded []Shape
+	nodes     []bvhNode
+	bounds    math.BoundingBox
+}
+
+// NewBVH allocates a BVH, makes it the parent of the shapes and builds the hierarchy. Like for a Group,
+// a shape must not be part of another container. To convert a loaded model, pass the children of
+// its groups, e.g. NewBVH(group.Children()...) and discard the group afterwards.
+func NewBVH(shapes ...Shape) *BVH {
+	b := &BVH{baseShape: newBaseShape(), children: append([]Shape(nil), shapes...)}
+	for _, s := range shapes {
+		s.SetParent(b)
+	}
+
+	b.build()
+	return b
+}
+
+// Children returns the shapes in their original order. The slice must not be modified.
+func (b *BVH) Children() []Shape {
+	return b.children
+}
+
+// Bounds returns the cached box around all shapes.
+func (b *BVH) Bounds() math.BoundingBox {
+	return b.bounds
+}
+
+// LocalIntersect traverses the hierarchy and appends the intersections of the shapes in all leaves,
+// whose bounds are hit, sorted by t.
+func (b *BVH) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	start := len(xs)
+	for _, s := range b.unbounded {
+		xs = Intersect(s, ray, xs)
+	}
+
+	if len(b.nodes) > 0 {
+		// a zero component becomes an infinite reciprocal, which is handled by the slab test
+		origin := [3]float32{ray.Origin.X, ray.Origin.Y, ray.Origin.Z}
+		invDir := [3]float32{1 / ray.Direction.X, 1 / ray.Direction.Y, 1 / ray.Direction.Z}
+
+		var buf [64]int32
+		stack := append(buf[:0], 0)
+		for len(stack) > 0 {
+			idx := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			n := &b.nodes[idx]
+			if !n.intersects(&origin, &invDir) {
+				continue
+			}
+
+			if n.count > 0 {
+				for _, s := range b.shapes[n.offset : n.offset+n.count] {
+					xs = Intersect(s, ray, xs)
+				}
+
+				continue
+			}
+
+			stack = append(stack, n.offset, idx+1)
+		}
+	}
+
+	sort.Sort(xs[start:])
+	return xs
+}
+
+// LocalNormalAt must not be called, because a BVH has no surface. The normals are always calculated
+// by the shapes, which have been hit.
+func (b *BVH) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	panic("tracer: a bvh has no normal")
+}
+
+// updateBounds rebuilds the hierarchy and notifies the parent.
+func (b *BVH) updateBounds() {
+	b.build()
+	boundsChanged(b.parent)
+}
+
+func (b *BVH) build() {
+	b.bounds = math.EmptyBoundingBox()
+	b.unbounded = nil
+	prims := make([]bvhPrimitive, 0, len(b.children))
+	for _, c := range b.children {
+		bounds := ParentSpaceBounds(c)
+		b.bounds.Merge(&bounds)
+		if !isFinite(&bounds) {
+			b.unbounded = append(b.unbounded, c)
+			continue
+		}
+
+		prims = append(prims, bvhPrimitive{
+			shape:  c,
+			bounds: bounds,
+			centroid: [3]float32{
+				(bounds.Min.X + bounds.Max.X) / 2,
+				(bounds.Min.Y + bounds.Max.Y) / 2,
+				(bounds.Min.Z + bounds.Max.Z) / 2,
+			},
+		})
+	}
+
+	// a binary tree with n leaves has at most 2n-1 nodes
+	b.nodes = make([]bvhNode, 0, 2*len(prims))
+	if len(prims) > 0 {
+		b.buildNode(prims, 0)
+	}
+
+	b.shapes = make([]Shape, len(prims))
+	for i := range prims {
+		b.shapes[i] = prims[i].shape
+	}
+}
+
+// buildNode appends the node for the primitives and recursively its children. The offset is the index
+// of the first primitive within all primitives.
+func (b *BVH) buildNode(prims []bvhPrimitive, offset int) {
+	bounds := math.EmptyBoundingBox()
+	centroids := math.EmptyBoundingBox()
+	for i := range prims {
+		bounds.Merge(&prims[i].bounds)
+		c := math.NewPoint(prims[i].centroid[0], prims[i].centroid[1], prims[i].centroid[2])
+		centroids.AddPoint(&c)
+	}
+
+	idx := len(b.nodes)
+	b.nodes = append(b.nodes, bvhNode{
+		min: [3]float32{bounds.Min.X, bounds.Min.Y, bounds.Min.Z},
+		max: [3]float32{bounds.Max.X, bounds.Max.Y, bounds.Max.Z},
+	})
+
+	split := partitionSAH(prims, &bounds, &centroids)
+	if split == 0 {
+		b.nodes[idx].offset = int32(offset)
+		b.nodes[idx].count = int32(len(prims))
+		return
+	}
+
+	b.buildNode(prims[:split], offset)
+	b.nodes[idx].offset = int32(len(b.nodes))
+	b.buildNode(prims[split:], offset+split)
+}
+
+// partitionSAH finds the cheapest split by the surface area heuristic, reorders the primitives and returns
+// the number of primitives on the left side. It returns 0, if a leaf is cheaper.
+func partitionSAH(prims []bvhPrimitive, bounds, centroids *math.BoundingBox) int {
+	if len(prims) <= 1 {
+		return 0
+	}
+
+	axis := 0
+	extent := [3]float32{
+		centroids.Max.X - centroids.Min.X,
+		centroids.Max.Y - centroids.Min.Y,
+		centroids.Max.Z - centroids.Min.Z,
+	}
+
+	if extent[1] > extent[axis] {
+		axis = 1
+	}
+
+	if extent[2] > extent[axis] {
+		axis = 2
+	}
+
+	if extent[axis] <= 0 {
+		// all centroids are equal, so no split separates them and the halves are arbitrary
+		if len(prims) <= bvhMaxLeafSize {
+			return 0
+		}
+
+		return len(prims) / 2
+	}
+
+	lo := [3]float32{centroids.Min.X, centroids.Min.Y, centroids.Min.Z}[axis]
+	bucketOf := func(p *bvhPrimitive) int {
+		i := int(bvhBuckets * (p.centroid[axis] - lo) / extent[axis])
+		if i >= bvhBuckets {
+			i = bvhBuckets - 1
+		}
+
+		return i
+	}
+
+	var counts [bvhBuckets]int
+	var boxes [bvhBuckets]math.BoundingBox
+	for i := range boxes {
+		boxes[i] = math.EmptyBoundingBox()
+	}
+
+	for i := range prims {
+		bucket := bucketOf(&prims[i])
+		counts[bucket]++
+		boxes[bucket].Merge(&prims[i].bounds)
+	}
+
+	// sweep from the right to know the area and count right of each split plane
+	var rightAreas [bvhBuckets]float32
+	var rightCounts [bvhBuckets]int
+	acc := math.EmptyBoundingBox()
+	n := 0
+	for i := bvhBuckets - 1; i > 0; i-- {
+		acc.Merge(&boxes[i])
+		n += counts[i]
+		rightAreas[i] = acc.SurfaceArea()
+		rightCounts[i] = n
+	}
+
+	// the split after bucket i with the lowest cost wins
+	best := -1
+	bestCost := math.Inf(1)
+	totalArea := bounds.SurfaceArea()
+	acc = math.EmptyBoundingBox()
+	n = 0
+	for i := 0; i < bvhBuckets-1; i++ {
+		acc.Merge(&boxes[i])
+		n += counts[i]
+		if n == 0 || rightCounts[i+1] == 0 {
+			continue
+		}
+
+		cost := bvhTraversalCost + (float32(n)*acc.SurfaceArea()+float32(rightCounts[i+1])*rightAreas[i+1])/totalArea
+		if cost < bestCost {
+			best = i
+			bestCost = cost
+		}
+	}
+
+	leafCost := float32(len(prims))
+	if len(prims) <= bvhMaxLeafSize && !(bestCost < leafCost) {
+		return 0
+	}
+
+	if best < 0 {
+		// the costs are not comparable, e.g. because all bounds are flat
+		return len(prims) / 2
+	}
+
+	left := 0
+	for i := range prims {
+		if bucketOf(&prims[i]) <= best {
+			prims[i], prims[left] = prims[left], prims[i]
+			left++
+		}
+	}
+
+	return left
+}
+
+// isFinite returns true, if no component of the bounds is infinite or NaN.
+func isFinite(b *math.BoundingBox) bool {
+	for _, f := range [6]float32{b.Min.X, b.Min.Y, b.Min.Z, b.Max.X, b.Max.Y, b.Max.Z} {
+		// NaN fails every comparison
+		if !(math.Abs(f) < math.Inf(1)) {
+			return false
+		}
+	}
+
+	return true
+}
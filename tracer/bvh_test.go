@@ -0,0 +1,210 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"math/rand"
+	"strconv"
+	"testing"
+	"unsafe"
+)
+
+// randomTriangles creates small triangles, which are scattered within a cube of the given size.
+func randomTriangles(rnd *rand.Rand, count int, size float32) []Shape {
+	point := func(center *math.Vec4f) math.Vec4f {
+		return math.NewPoint(center.X+rnd.Float32()-0.5, center.Y+rnd.Float32()-0.5, center.Z+rnd.Float32()-0.5)
+	}
+
+	res := make([]Shape, count)
+	for i := range res {
+		center := math.NewPoint((rnd.Float32()-0.5)*size, (rnd.Float32()-0.5)*size, (rnd.Float32()-0.5)*size)
+		res[i] = NewTriangle(point(&center), point(&center), point(&center))
+	}
+
+	return res
+}
+
+func TestBVHNode_Size(t *testing.T) {
+	if size := unsafe.Sizeof(bvhNode{}); size != 32 {
+		t.Errorf("Sizeof(bvhNode{}) = %v, want 32", size)
+	}
+}
+
+func TestNewBVH(t *testing.T) {
+	s1 := NewSphere()
+	s1.SetTransform(math.Translation(-3, 0, 0))
+	s2 := NewCube()
+	s2.SetTransform(math.Translation(0, 5, 0))
+	p := NewPlane()
+	b := NewBVH(s1, s2, p)
+
+	if children := b.Children(); len(children) != 3 || children[0] != s1 || children[1] != s2 || children[2] != p {
+		t.Errorf("Children() = %v, want [%v %v %v]", children, s1, s2, p)
+	}
+
+	for _, s := range b.Children() {
+		if s.Parent() != b {
+			t.Errorf("Parent() = %v, want %v", s.Parent(), b)
+		}
+	}
+
+	inf := math.Inf(1)
+	want := math.NewBoundingBox(math.NewPoint(-inf, -1, -inf), math.NewPoint(inf, 6, inf))
+	if got := b.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	if len(b.unbounded) != 1 || b.unbounded[0] != p {
+		t.Errorf("unbounded = %v, want [%v]", b.unbounded, p)
+	}
+
+	if !Includes(b, s2) {
+		t.Errorf("Includes() = false, want true")
+	}
+
+	// an empty bvh is valid
+	b = NewBVH()
+	r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+	if xs := b.LocalIntersect(&r, nil); len(xs) != 0 {
+		t.Errorf("len(xs) = %v, want 0", len(xs))
+	}
+}
+
+func TestBVH_Structure(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	shapes := randomTriangles(rnd, 1000, 50)
+	b := NewBVH(shapes...)
+
+	seen := map[Shape]bool{}
+	var visit func(idx int32) math.BoundingBox
+	visit = func(idx int32) math.BoundingBox {
+		n := &b.nodes[idx]
+		nodeBounds := math.NewBoundingBox(math.NewPoint(n.min[0], n.min[1], n.min[2]), math.NewPoint(n.max[0], n.max[1], n.max[2]))
+		if n.count == 0 {
+			left := visit(idx + 1)
+			right := visit(n.offset)
+			if !nodeBounds.ContainsBox(&left) || !nodeBounds.ContainsBox(&right) {
+				t.Fatalf("node %d does not contain its children", idx)
+			}
+
+			return nodeBounds
+		}
+
+		if n.count > bvhMaxLeafSize {
+			t.Errorf("leaf %d contains %d shapes", idx, n.count)
+		}
+
+		for _, s := range b.shapes[n.offset : n.offset+n.count] {
+			if seen[s] {
+				t.Errorf("shape %v is referenced twice", s)
+			}
+
+			seen[s] = true
+			bounds := ParentSpaceBounds(s)
+			if !nodeBounds.ContainsBox(&bounds) {
+				t.Errorf("leaf %d does not contain %v", idx, s)
+			}
+		}
+
+		return nodeBounds
+	}
+
+	visit(0)
+	if len(seen) != len(shapes) {
+		t.Errorf("%d shapes are referenced, want %d", len(seen), len(shapes))
+	}
+
+	if len(b.nodes) > 2*len(shapes)-1 {
+		t.Errorf("len(nodes) = %v, want at most %v", len(b.nodes), 2*len(shapes)-1)
+	}
+}
+
+func TestBVH_LocalIntersect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	group := NewGroup()
+	group.AddChild(randomTriangles(rnd, 500, 20)...)
+	group.AddChild(NewPlane())
+	rnd = rand.New(rand.NewSource(2))
+	b := NewBVH(append(randomTriangles(rnd, 500, 20), NewPlane())...)
+
+	// the bvh must find exactly the same intersections as a plain group
+	for i := 0; i < 500; i++ {
+		origin := math.NewPoint((rnd.Float32()-0.5)*30, (rnd.Float32()-0.5)*30, -20)
+		direction := math.NewVector((rnd.Float32()-0.5)*0.5, (rnd.Float32()-0.5)*0.5, 1)
+		if i%10 == 0 {
+			// axis parallel rays exercise the infinite reciprocals
+			direction = math.NewVector(0, 0, 1)
+		}
+
+		r := math.NewRay(origin, direction)
+		want := group.LocalIntersect(&r, nil)
+		got := b.LocalIntersect(&r, nil)
+		if len(got) != len(want) {
+			t.Fatalf("%d: len(xs) = %v, want %v", i, len(got), len(want))
+		}
+
+		for j := range want {
+			if got[j].T != want[j].T {
+				t.Errorf("%d: xs[%d].T = %v, want %v", i, j, got[j].T, want[j].T)
+			}
+		}
+	}
+}
+
+func TestBVH_Degenerated(t *testing.T) {
+	tests := []struct {
+		count int
+	}{
+		{1},
+		{bvhMaxLeafSize},
+		{bvhMaxLeafSize + 1},
+		{100},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			// shapes with equal bounds cannot be separated by any split
+			shapes := make([]Shape, tt.count)
+			for j := range shapes {
+				shapes[j] = NewSphere()
+			}
+
+			b := NewBVH(shapes...)
+			r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+			if xs := b.LocalIntersect(&r, nil); len(xs) != 2*tt.count {
+				t.Errorf("len(xs) = %v, want %v", len(xs), 2*tt.count)
+			}
+		})
+	}
+}
+
+func TestBVH_Rebuild(t *testing.T) {
+	s := NewSphere()
+	b := NewBVH(s, NewSphere())
+	g := NewGroup()
+	g.AddChild(b)
+
+	s.SetTransform(math.Translation(10, 0, 0))
+	r := math.NewRay(math.NewPoint(10, 0, -5), math.NewVector(0, 0, 1))
+	xs := Intersect(g, &r, nil)
+	if len(xs) != 2 || xs[0].Object != s {
+		t.Errorf("xs = %v, want 2 intersections with %v", xs, s)
+	}
+
+	want := math.NewBoundingBox(math.NewPoint(-1, -1, -1), math.NewPoint(11, 1, 1))
+	if got := g.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"context"
+	"github.com/torbenschinke/rtc/canvas"
+	"github.com/torbenschinke/rtc/math"
+	"runtime"
+	"sync"
+)
+
+// DefaultTileSize is the default edge length of the square tiles, which are rendered in parallel.
+const DefaultTileSize = 32
+
+// A Camera maps the canvas one unit in front of the eye. The camera looks towards -z and is moved
+// around by its view transformation, see also math.ViewTransform.
+type Camera struct {
+	hsize, vsize int
+	fieldOfView  float32
+	transform    math.Mat4f
+	inverse      math.Mat4f
+	halfWidth    float32
+	halfHeight   float32
+	pixelSize    float32
+}
+
+// NewCamera creates a camera for a canvas of hsize * vsize pixels. The field of view is the
+// horizontal or vertical angle in radians, whichever side of the canvas is longer.
+func NewCamera(hsize, vsize int, fieldOfView float32) *Camera {
+	c := &Camera{
+		hsize:       hsize,
+		vsize:       vsize,
+		fieldOfView: fieldOfView,
+		transform:   math.Identity(),
+		inverse:     math.Identity(),
+	}
+
+	halfView := math.Tan(fieldOfView / 2)
+	aspect := float32(hsize) / float32(vsize)
+	if aspect >= 1 {
+		c.halfWidth = halfView
+		c.halfHeight = halfView / aspect
+	} else {
+		c.halfWidth = halfView * aspect
+		c.halfHeight = halfView
+	}
+
+	c.pixelSize = c.halfWidth * 2 / float32(hsize)
+
+	return c
+}
+
+// HSize returns the horizontal size of the canvas in pixels.
+func (c *Camera) HSize() int {
+	return c.hsize
+}
+
+// VSize returns the vertical size of the canvas in pixels.
+func (c *Camera) VSize() int {
+	return c.vsize
+}
+
+// FieldOfView returns the angle in radians.
+func (c *Camera) FieldOfView() float32 {
+	return c.fieldOfView
+}
+
+// PixelSize returns the size of a single pixel in world units on the canvas.
+func (c *Camera) PixelSize() float32 {
+	return c.pixelSize
+}
+
+// Transform returns the view transformation.
+func (c *Camera) Transform() *math.Mat4f {
+	return &c.transform
+}
+
+// SetTransform updates the view transformation and its cached inverse. It panics, if the matrix is not
+// invertible, e.g. because math.ViewTransform got a zero or parallel up vector.
+func (c *Camera) SetTransform(m math.Mat4f) {
+	inverse := m
+	if !inverse.Invert() {
+		panic("tracer: the view transformation is not invertible")
+	}
+
+	c.transform = m
+	c.inverse = inverse
+}
+
+// RayForPixel returns the ray from the eye through the center of the given pixel.
+func (c *Camera) RayForPixel(px, py int) math.Ray {
+	// offset from the canvas edge to the pixel center
+	xoffset := (float32(px) + 0.5) * c.pixelSize
+	yoffset := (float32(py) + 0.5) * c.pixelSize
+
+	// untransformed coordinates of the pixel in world space, remember that the camera looks towards -z
+	worldX := c.halfWidth - xoffset
+	worldY := c.halfHeight - yoffset
+
+	pixel := math.NewPoint(worldX, worldY, -1)
+	pixel.Transform(&c.inverse)
+	origin := math.NewPoint(0, 0, 0)
+	origin.Transform(&c.inverse)
+
+	direction := pixel
+	direction.Sub(&origin)
+	direction.Normalize()
+
+	return math.NewRay(origin, direction)
+}
+
+// RenderOptions configures the parallel rendering. The zero value is valid and uses the defaults.
+type RenderOptions struct {
+	Workers  int // the number of goroutines, runtime.GOMAXPROCS(0) if not positive
+	TileSize int // the edge length of the tiles in pixels, DefaultTileSize if not positive
+	// Progress is called after each finished tile with the number of finished and of all tiles. It is
+	// called from the goroutine, which called RenderContext, so it needs no synchronization.
+	Progress func(done, total int)
+}
+
+// A tile is the rectangle from x0, y0 (inclusive) to x1, y1 (exclusive) of the canvas.
+type tile struct {
+	x0, y0, x1, y1 int
+}
+
+// Render traces a ray for each pixel of the canvas through the world, using all available CPUs.
+// See RenderContext.
+func (c *Camera) Render(w *World) canvas.Canvas {
+	img, _ := c.RenderContext(context.Background(), w, RenderOptions{})
+	return img
+}
+
+// RenderContext splits the canvas into tiles, which are rendered by a pool of workers. Each pixel is
+// calculated exactly like by a single goroutine, so the result is identical regardless of the options.
+// The world must not be modified while rendering. If the context is cancelled, no more tiles are
+// started and the partially rendered canvas is returned together with the context error.
+func (c *Camera) RenderContext(ctx context.Context, w *World, opts RenderOptions) (canvas.Canvas, error) {
+	img := canvas.NewCanvas(c.hsize, c.vsize)
+	tiles := c.tiles(opts.TileSize)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if workers > len(tiles) {
+		workers = len(tiles)
+	}
+
+	jobs := make(chan tile)
+	finished := make(chan struct{}, workers) // a slow progress callback blocks the workers only briefly
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				c.renderTile(w, &img, t)
+				finished <- struct{}{}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, t := range tiles {
+			// check first, because select chooses randomly if both cases are ready
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case jobs <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	done := 0
+	for range finished {
+		done++
+		if opts.Progress != nil {
+			opts.Progress(done, len(tiles))
+		}
+	}
+
+	if done < len(tiles) {
+		return img, ctx.Err()
+	}
+
+	return img, nil
+}
+
+// tiles returns the tiles row by row. The tiles at the right and bottom border may be smaller.
+func (c *Camera) tiles(size int) []tile {
+	if size <= 0 {
+		size = DefaultTileSize
+	}
+
+	var res []tile
+	for y := 0; y < c.vsize; y += size {
+		for x := 0; x < c.hsize; x += size {
+			t := tile{x0: x, y0: y, x1: x + size, y1: y + size}
+			if t.x1 > c.hsize {
+				t.x1 = c.hsize
+			}
+
+			if t.y1 > c.vsize {
+				t.y1 = c.vsize
+			}
+
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+// renderTile writes the pixels of the tile. Tiles never overlap, so concurrent calls are safe.
+func (c *Camera) renderTile(w *World, img *canvas.Canvas, t tile) {
+	for y := t.y0; y < t.y1; y++ {
+		for x := t.x0; x < t.x1; x++ {
+			r := c.RayForPixel(x, y)
+			color := w.ColorAt(&r)
+			img.Write(x, y, &color)
+		}
+	}
+}
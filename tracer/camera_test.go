@@ -0,0 +1,193 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"context"
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestCamera_PixelSize(t *testing.T) {
+	tests := []struct {
+		hsize, vsize int
+		res          float32
+	}{
+		{200, 125, 0.01},
+		{125, 200, 0.01},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCamera(tt.hsize, tt.vsize, math.Pi/2)
+			if got := c.PixelSize(); !math.Equalf(got, tt.res) {
+				t.Errorf("PixelSize() = %v, want %v", got, tt.res)
+			}
+		})
+	}
+}
+
+func TestCamera_RayForPixel(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		transform         math.Mat4f
+		x, y              int
+		origin, direction math.Vec4f
+	}{
+		{math.Identity(), 100, 50, math.NewPoint(0, 0, 0), math.NewVector(0, 0, -1)},
+		{math.Identity(), 0, 0, math.NewPoint(0, 0, 0), math.NewVector(0.66519, 0.33259, -0.66851)},
+		{
+			math.Identity().Translate(0, -2, 5).RotateY(math.Pi / 4), 100, 50,
+			math.NewPoint(0, 2, -5), math.NewVector(sqrt2, 0, -sqrt2),
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCamera(201, 101, math.Pi/2)
+			c.SetTransform(tt.transform)
+			r := c.RayForPixel(tt.x, tt.y)
+			if !equalsApprox(&r.Origin, &tt.origin) {
+				t.Errorf("Origin = %v, want %v", r.Origin, tt.origin)
+			}
+
+			if !equalsApprox(&r.Direction, &tt.direction) {
+				t.Errorf("Direction = %v, want %v", r.Direction, tt.direction)
+			}
+		})
+	}
+}
+
+func TestCamera_Render(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	from := math.NewPoint(0, 0, -5)
+	to := math.NewPoint(0, 0, 0)
+	up := math.NewVector(0, 1, 0)
+	c.SetTransform(math.ViewTransform(from, to, up))
+
+	img := c.Render(w)
+	want := math.NewRGB(0.38066, 0.47583, 0.2855)
+	if got := img.Read(5, 5); !equalsApprox(got, &want) {
+		t.Errorf("Read(5, 5) = %v, want %v", got, want)
+	}
+}
+
+// renderTestScene returns the default world with a reflective floor and a camera looking at it.
+func renderTestScene() (*World, *Camera) {
+	w := defaultWorld()
+	floor := NewPlane()
+	floor.SetTransform(math.Translation(0, -1, 0))
+	floor.Material().Reflective = 0.5
+	floor.Material().Pattern = NewCheckerPattern(NewSolidPattern(white), NewSolidPattern(black))
+	w.Objects = append(w.Objects, floor)
+
+	c := NewCamera(37, 23, math.Pi/3)
+	c.SetTransform(math.ViewTransform(math.NewPoint(0, 1.5, -5), math.NewPoint(0, 0, 0), math.NewVector(0, 1, 0)))
+	return w, c
+}
+
+func TestCamera_RenderContext(t *testing.T) {
+	w, c := renderTestScene()
+
+	// the result of a single goroutine without tiles
+	want := make([]math.Vec4f, 0, c.HSize()*c.VSize())
+	for y := 0; y < c.VSize(); y++ {
+		for x := 0; x < c.HSize(); x++ {
+			r := c.RayForPixel(x, y)
+			want = append(want, w.ColorAt(&r))
+		}
+	}
+
+	tests := []struct {
+		opts  RenderOptions
+		tiles int
+	}{
+		{RenderOptions{}, 2},
+		{RenderOptions{Workers: 1, TileSize: 1}, 37 * 23},
+		{RenderOptions{Workers: 3, TileSize: 5}, 8 * 5},
+		{RenderOptions{Workers: 64, TileSize: 16}, 3 * 2},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var calls []int
+			tt.opts.Progress = func(done, total int) {
+				if total != tt.tiles {
+					t.Errorf("total = %v, want %v", total, tt.tiles)
+				}
+
+				calls = append(calls, done)
+			}
+
+			img, err := c.RenderContext(context.Background(), w, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for j := range want {
+				if img.Buffer[j] != want[j] {
+					t.Fatalf("pixel %d = %v, want %v", j, img.Buffer[j], want[j])
+				}
+			}
+
+			if len(calls) != tt.tiles {
+				t.Fatalf("Progress called %d times, want %d", len(calls), tt.tiles)
+			}
+
+			for j, done := range calls {
+				if done != j+1 {
+					t.Errorf("done = %v, want %v", done, j+1)
+				}
+			}
+		})
+	}
+}
+
+func TestCamera_RenderContextCancel(t *testing.T) {
+	w, c := renderTestScene()
+
+	// cancelled before the start
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	_, err := c.RenderContext(ctx, w, RenderOptions{Progress: func(done, total int) { calls++ }})
+	if err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+
+	if calls != 0 {
+		t.Errorf("Progress called %d times, want 0", calls)
+	}
+
+	// cancelled while rendering
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	tiles := 0
+	_, err = c.RenderContext(ctx, w, RenderOptions{
+		Workers:  2,
+		TileSize: 1,
+		Progress: func(done, total int) {
+			tiles = total
+			cancel()
+		},
+	})
+
+	if err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+
+	if tiles != 37*23 {
+		t.Errorf("total = %v, want %v", tiles, 37*23)
+	}
+}
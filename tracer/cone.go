@@ -0,0 +1,98 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Cone is a double-napped cone around the y axis, whose radius equals the absolute y value, so that
+// both tips meet at the origin. Just like a Cylinder, it can be truncated and closed.
+type Cone struct {
+	baseShape
+	Minimum, Maximum float32
+	Closed           bool
+}
+
+// NewCone allocates an infinite double cone with the identity transformation.
+func NewCone() *Cone {
+	return &Cone{
+		baseShape: newBaseShape(),
+		Minimum:   math.Inf(-1),
+		Maximum:   math.Inf(1),
+	}
+}
+
+// LocalIntersect appends the intersections with the walls and with the caps.
+func (c *Cone) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	o, d := &ray.Origin, &ray.Direction
+	a := d.X*d.X - d.Y*d.Y + d.Z*d.Z
+	b := 2*o.X*d.X - 2*o.Y*d.Y + 2*o.Z*d.Z
+	cc := o.X*o.X - o.Y*o.Y + o.Z*o.Z
+
+	switch {
+	case math.Equalf(a, 0) && !math.Equalf(b, 0):
+		// the ray is parallel to one of the halves, so it hits the other half only once and the
+		// quadratic equation degenerates to the linear equation bt + c = 0
+		t := -cc / b
+		y := o.Y + t*d.Y
+		if c.Minimum < y && y < c.Maximum {
+			xs = append(xs, Intersection{T: t, Object: c})
+		}
+	case !math.Equalf(a, 0):
+		disc := b*b - 4*a*cc
+		if disc < -math.Epsilon {
+			return xs
+		}
+
+		// a tangent ray may produce a tiny negative discriminant due to float32 precision
+		if disc < 0 {
+			disc = 0
+		}
+
+		xs = appendWalls(c, ray, xs, a, b, disc, c.Minimum, c.Maximum)
+	}
+
+	if c.Closed {
+		xs = intersectCap(c, ray, c.Minimum, math.Abs(c.Minimum), xs)
+		xs = intersectCap(c, ray, c.Maximum, math.Abs(c.Maximum), xs)
+	}
+
+	return xs
+}
+
+// LocalNormalAt returns the normal of the wall or of a cap.
+func (c *Cone) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	dist := point.X*point.X + point.Z*point.Z
+
+	if dist < c.Maximum*c.Maximum && point.Y >= c.Maximum-math.Epsilon {
+		return math.NewVector(0, 1, 0)
+	}
+
+	if dist < c.Minimum*c.Minimum && point.Y <= c.Minimum+math.Epsilon {
+		return math.NewVector(0, -1, 0)
+	}
+
+	y := math.Sqrt(dist)
+	if point.Y > 0 {
+		y = -y
+	}
+
+	return math.NewVector(point.X, y, point.Z)
+}
+
+// Bounds returns a box from Minimum to Maximum, whose radius is the larger absolute limit.
+func (c *Cone) Bounds() math.BoundingBox {
+	r := math.Max(math.Abs(c.Minimum), math.Abs(c.Maximum))
+	return math.NewBoundingBox(math.NewPoint(-r, c.Minimum, -r), math.NewPoint(r, c.Maximum, r))
+}
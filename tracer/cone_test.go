@@ -0,0 +1,73 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestCone_LocalIntersect(t *testing.T) {
+	tests := []struct {
+		min, max  float32
+		closed    bool
+		origin    math.Vec4f
+		direction math.Vec4f
+		res       []float32
+	}{
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1), []float32{5, 5}},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 0, -5), math.NewVector(1, 1, 1), []float32{8.66025, 8.66025}},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(1, 1, -5), math.NewVector(-0.5, -1, 1), []float32{4.55006, 49.44994}},
+
+		// parallel to one of the halves
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 0, -1), math.NewVector(0, 1, 1), []float32{0.70711}},
+
+		// closed caps
+		{-0.5, 0.5, true, math.NewPoint(0, 0, -5), math.NewVector(0, 1, 0), nil},
+		{-0.5, 0.5, true, math.NewPoint(0, 0, -0.25), math.NewVector(0, 1, 1), []float32{0.17678, 0.70711}},
+		{-0.5, 0.5, true, math.NewPoint(0, 0, -0.25), math.NewVector(0, 1, 0), []float32{-0.25, 0.25, -0.5, 0.5}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCone()
+			c.Minimum = tt.min
+			c.Maximum = tt.max
+			c.Closed = tt.closed
+			r := math.NewRay(tt.origin, normalized(tt.direction))
+			xs := c.LocalIntersect(&r, nil)
+			assertTs(t, xs, tt.res)
+		})
+	}
+}
+
+func TestCone_LocalNormalAt(t *testing.T) {
+	tests := []struct {
+		point math.Vec4f
+		res   math.Vec4f
+	}{
+		{math.NewPoint(0, 0, 0), math.NewVector(0, 0, 0)},
+		{math.NewPoint(1, 1, 1), math.NewVector(1, -math.Sqrt(2), 1)},
+		{math.NewPoint(-1, -1, 0), math.NewVector(-1, 1, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCone()
+			if n := c.LocalNormalAt(&tt.point, nil); !n.Equals(&tt.res) {
+				t.Errorf("LocalNormalAt(%v) = %v, want %v", tt.point, n, tt.res)
+			}
+		})
+	}
+}
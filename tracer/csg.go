@@ -0,0 +1,165 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"sort"
+)
+
+// A CSGOperation defines how the two shapes of a CSG are combined.
+type CSGOperation int
+
+const (
+	// CSGUnion keeps the surfaces of both shapes, which are not inside the other shape.
+	CSGUnion CSGOperation = iota
+	// CSGIntersection keeps only the surfaces, where both shapes overlap.
+	CSGIntersection
+	// CSGDifference keeps the surfaces of the left shape, which are not inside the right shape,
+	// and the surfaces of the right shape, which are inside the left shape.
+	CSGDifference
+)
+
+// A CSG applies a boolean operation to two shapes (constructive solid geometry). Both shapes should be
+// closed, because the operation decides by the intersections whether a ray is inside or outside of a shape.
+type CSG struct {
+	baseShape
+	operation   CSGOperation
+	left, right Shape
+}
+
+// NewCSG allocates a CSG and makes it the parent of both shapes.
+func NewCSG(operation CSGOperation, left, right Shape) *CSG {
+	c := &CSG{baseShape: newBaseShape(), operation: operation, left: left, right: right}
+	left.SetParent(c)
+	right.SetParent(c)
+
+	return c
+}
+
+// Operation returns the boolean operation.
+func (c *CSG) Operation() CSGOperation {
+	return c.operation
+}
+
+// Left returns the left operand.
+func (c *CSG) Left() Shape {
+	return c.left
+}
+
+// Right returns the right operand.
+func (c *CSG) Right() Shape {
+	return c.right
+}
+
+// LocalIntersect intersects the ray with both shapes and appends those intersections sorted by t, which
+// are allowed by the operation.
+func (c *CSG) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	start := len(xs)
+	xs = Intersect(c.left, ray, xs)
+	xs = Intersect(c.right, ray, xs)
+	sort.Sort(xs[start:])
+
+	return xs[:start+len(c.FilterIntersections(xs[start:]))]
+}
+
+// FilterIntersections removes all intersections, which are not allowed by the operation. The intersections
+// must be sorted by t. The filtering is done in place, so the returned slice shares the array with xs.
+func (c *CSG) FilterIntersections(xs Intersections) Intersections {
+	// both shapes are closed, so the ray starts outside of both
+	inLeft := false
+	inRight := false
+	res := xs[:0]
+	for _, x := range xs {
+		leftHit := Includes(c.left, x.Object)
+		if IntersectionAllowed(c.operation, leftHit, inLeft, inRight) {
+			res = append(res, x)
+		}
+
+		if leftHit {
+			inLeft = !inLeft
+		} else {
+			inRight = !inRight
+		}
+	}
+
+	return res
+}
+
+// Bounds returns the box around both shapes.
+func (c *CSG) Bounds() math.BoundingBox {
+	b := ParentSpaceBounds(c.left)
+	r := ParentSpaceBounds(c.right)
+	b.Merge(&r)
+	return b
+}
+
+// Divide builds the bounding volume hierarchies of both shapes, see Group.Divide.
+func (c *CSG) Divide(threshold int) {
+	divide(c.left, threshold)
+	divide(c.right, threshold)
+}
+
+// updateBounds notifies the parent, because the bounds of a CSG are not cached.
+func (c *CSG) updateBounds() {
+	boundsChanged(c.parent)
+}
+
+// LocalNormalAt must not be called, because a CSG has no surface on its own. The normals are always calculated
+// by the shapes, which have been hit.
+func (c *CSG) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	panic("tracer: a csg has no normal")
+}
+
+// IntersectionAllowed decides if an intersection is part of the combined surface. The leftHit is true if
+// the left shape has been hit, inLeft and inRight tell if the hit is inside of the left or right shape.
+func IntersectionAllowed(operation CSGOperation, leftHit, inLeft, inRight bool) bool {
+	switch operation {
+	case CSGUnion:
+		return (leftHit && !inRight) || (!leftHit && !inLeft)
+	case CSGIntersection:
+		return (leftHit && inRight) || (!leftHit && inLeft)
+	case CSGDifference:
+		return (leftHit && !inRight) || (!leftHit && inLeft)
+	default:
+		return false
+	}
+}
+
+// Includes returns true, if b is a or b is contained by a, which descends into groups, BVHs and CSGs.
+func Includes(a, b Shape) bool {
+	switch s := a.(type) {
+	case *Group:
+		for _, c := range s.children {
+			if Includes(c, b) {
+				return true
+			}
+		}
+
+		return false
+	case *BVH:
+		for _, c := range s.children {
+			if Includes(c, b) {
+				return true
+			}
+		}
+
+		return false
+	case *CSG:
+		return Includes(s.left, b) || Includes(s.right, b)
+	default:
+		return a == b
+	}
+}
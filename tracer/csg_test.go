@@ -0,0 +1,231 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestNewCSG(t *testing.T) {
+	s1 := NewSphere()
+	s2 := NewCube()
+	c := NewCSG(CSGUnion, s1, s2)
+	if c.Operation() != CSGUnion || c.Left() != s1 || c.Right() != s2 {
+		t.Errorf("NewCSG() = %v %v %v", c.Operation(), c.Left(), c.Right())
+	}
+
+	if s1.Parent() != c || s2.Parent() != c {
+		t.Errorf("Parent() = %v %v, want %v", s1.Parent(), s2.Parent(), c)
+	}
+}
+
+func TestIntersectionAllowed(t *testing.T) {
+	tests := []struct {
+		op                       CSGOperation
+		leftHit, inLeft, inRight bool
+		res                      bool
+	}{
+		{CSGUnion, true, true, true, false},
+		{CSGUnion, true, true, false, true},
+		{CSGUnion, true, false, true, false},
+		{CSGUnion, true, false, false, true},
+		{CSGUnion, false, true, true, false},
+		{CSGUnion, false, true, false, false},
+		{CSGUnion, false, false, true, true},
+		{CSGUnion, false, false, false, true},
+
+		{CSGIntersection, true, true, true, true},
+		{CSGIntersection, true, true, false, false},
+		{CSGIntersection, true, false, true, true},
+		{CSGIntersection, true, false, false, false},
+		{CSGIntersection, false, true, true, true},
+		{CSGIntersection, false, true, false, true},
+		{CSGIntersection, false, false, true, false},
+		{CSGIntersection, false, false, false, false},
+
+		{CSGDifference, true, true, true, false},
+		{CSGDifference, true, true, false, true},
+		{CSGDifference, true, false, true, false},
+		{CSGDifference, true, false, false, true},
+		{CSGDifference, false, true, true, true},
+		{CSGDifference, false, true, false, true},
+		{CSGDifference, false, false, true, false},
+		{CSGDifference, false, false, false, false},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := IntersectionAllowed(tt.op, tt.leftHit, tt.inLeft, tt.inRight); got != tt.res {
+				t.Errorf("IntersectionAllowed() = %v, want %v", got, tt.res)
+			}
+		})
+	}
+}
+
+func TestCSG_FilterIntersections(t *testing.T) {
+	tests := []struct {
+		op     CSGOperation
+		x0, x1 int
+	}{
+		{CSGUnion, 0, 3},
+		{CSGIntersection, 1, 2},
+		{CSGDifference, 0, 1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s1 := NewSphere()
+			s2 := NewCube()
+			c := NewCSG(tt.op, s1, s2)
+			xs := Intersections{{T: 1, Object: s1}, {T: 2, Object: s2}, {T: 3, Object: s1}, {T: 4, Object: s2}}
+			want := Intersections{xs[tt.x0], xs[tt.x1]}
+
+			res := c.FilterIntersections(xs)
+			if len(res) != 2 || res[0] != want[0] || res[1] != want[1] {
+				t.Errorf("FilterIntersections() = %v, want %v", res, want)
+			}
+		})
+	}
+}
+
+func TestCSG_LocalIntersect(t *testing.T) {
+	// a ray misses
+	c := NewCSG(CSGUnion, NewSphere(), NewCube())
+	r := math.NewRay(math.NewPoint(0, 2, -5), math.NewVector(0, 0, 1))
+	if xs := c.LocalIntersect(&r, nil); len(xs) != 0 {
+		t.Errorf("len(xs) = %v, want 0", len(xs))
+	}
+
+	// a ray hits
+	s1 := NewSphere()
+	s2 := NewSphere()
+	s2.SetTransform(math.Translation(0, 0, 0.5))
+	c = NewCSG(CSGUnion, s1, s2)
+	r = math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+
+	// existing intersections must be kept untouched
+	other := Intersection{T: 42, Object: NewSphere()}
+	xs := c.LocalIntersect(&r, Intersections{other})
+	assertTs(t, xs, []float32{42, 4, 6.5})
+	if xs[0] != other || xs[1].Object != s1 || xs[2].Object != s2 {
+		t.Errorf("xs = %v", xs)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	s1 := NewSphere()
+	s2 := NewCube()
+	s3 := NewCylinder()
+	g := NewGroup()
+	g.AddChild(s1)
+	c := NewCSG(CSGDifference, g, s2)
+
+	tests := []struct {
+		a, b Shape
+		res  bool
+	}{
+		{s1, s1, true},
+		{s1, s2, false},
+		{g, s1, true},
+		{g, s2, false},
+		{c, s1, true},
+		{c, s2, true},
+		{c, s3, false},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := Includes(tt.a, tt.b); got != tt.res {
+				t.Errorf("Includes() = %v, want %v", got, tt.res)
+			}
+		})
+	}
+}
+
+func TestCSG_DifferenceWithGroup(t *testing.T) {
+	// the left side is a group, so the hits of its children must be recognized as left hits
+	left := NewGroup()
+	left.AddChild(NewCube())
+	right := NewSphere()
+	right.SetTransform(math.Translation(0, 0, -1))
+	c := NewCSG(CSGDifference, left, right)
+
+	r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+	xs := Intersect(c, &r, nil)
+	assertTs(t, xs, []float32{5, 6})
+	if xs[0].Object != right || xs[1].Object != left.Children()[0] {
+		t.Errorf("xs = %v", xs)
+	}
+}
+
+func TestCSG_Bounds(t *testing.T) {
+	left := NewSphere()
+	right := NewSphere()
+	right.SetTransform(math.Translation(2, 3, 4))
+	c := NewCSG(CSGDifference, left, right)
+
+	want := math.NewBoundingBox(math.NewPoint(-1, -1, -1), math.NewPoint(3, 4, 5))
+	if got := c.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	// a parent group is notified about transformed operands
+	g := NewGroup()
+	g.AddChild(c)
+	right.SetTransform(math.Translation(-2, -3, -4))
+	want = math.NewBoundingBox(math.NewPoint(-3, -4, -5), math.NewPoint(1, 1, 1))
+	if got := g.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+}
+
+func TestCSG_Divide(t *testing.T) {
+	s1 := NewSphere()
+	s1.SetTransform(math.Translation(-1.5, 0, 0))
+	s2 := NewSphere()
+	s2.SetTransform(math.Translation(1.5, 0, 0))
+	left := NewGroup()
+	left.AddChild(s1, s2)
+	s3 := NewSphere()
+	s3.SetTransform(math.Translation(0, 0, -1.5))
+	s4 := NewSphere()
+	s4.SetTransform(math.Translation(0, 0, 1.5))
+	right := NewGroup()
+	right.AddChild(s3, s4)
+	c := NewCSG(CSGDifference, left, right)
+	c.Divide(1)
+
+	tests := []struct {
+		group *Group
+		want  []Shape
+	}{
+		{left, []Shape{s1, s2}},
+		{right, []Shape{s3, s4}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			children := tt.group.Children()
+			if len(children) != len(tt.want) {
+				t.Fatalf("len(Children()) = %v, want %v", len(children), len(tt.want))
+			}
+
+			for j, want := range tt.want {
+				sub := children[j].(*Group)
+				if len(sub.Children()) != 1 || sub.Children()[0] != want {
+					t.Errorf("Children() = %v, want [%v]", sub.Children(), want)
+				}
+			}
+		})
+	}
+}
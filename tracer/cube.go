@@ -0,0 +1,108 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Cube is an axis aligned box from -1 to 1 in each dimension.
+type Cube struct {
+	baseShape
+}
+
+// NewCube allocates a cube with the identity transformation.
+func NewCube() *Cube {
+	return &Cube{baseShape: newBaseShape()}
+}
+
+// LocalIntersect treats the cube as six planes, grouped into three pairs of parallel planes (slabs). The
+// ray hits the cube, if the intervals of all three slabs overlap.
+func (c *Cube) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	xtmin, xtmax := checkAxis(ray.Origin.X, ray.Direction.X, -1, 1)
+	ytmin, ytmax := checkAxis(ray.Origin.Y, ray.Direction.Y, -1, 1)
+	ztmin, ztmax := checkAxis(ray.Origin.Z, ray.Direction.Z, -1, 1)
+
+	tmin := max3(xtmin, ytmin, ztmin)
+	tmax := min3(xtmax, ytmax, ztmax)
+	if tmin > tmax {
+		return xs
+	}
+
+	return append(xs, Intersection{T: tmin, Object: c}, Intersection{T: tmax, Object: c})
+}
+
+// LocalNormalAt returns the normal of the face, which belongs to the largest absolute component.
+func (c *Cube) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	x, y, z := math.Abs(point.X), math.Abs(point.Y), math.Abs(point.Z)
+	maxc := max3(x, y, z)
+
+	switch maxc {
+	case x:
+		return math.NewVector(point.X, 0, 0)
+	case y:
+		return math.NewVector(0, point.Y, 0)
+	default:
+		return math.NewVector(0, 0, point.Z)
+	}
+}
+
+// Bounds returns the cube itself.
+func (c *Cube) Bounds() math.BoundingBox {
+	return math.NewBoundingBox(math.NewPoint(-1, -1, -1), math.NewPoint(1, 1, 1))
+}
+
+// checkAxis returns the distances, at which the ray crosses the two planes at min and max of a single axis.
+func checkAxis(origin, direction, min, max float32) (tmin, tmax float32) {
+	tminNumerator := min - origin
+	tmaxNumerator := max - origin
+
+	if math.Abs(direction) >= math.Epsilon {
+		tmin = tminNumerator / direction
+		tmax = tmaxNumerator / direction
+	} else {
+		// parallel to the planes, so the ray is either always in between or never
+		tmin = tminNumerator * math.Inf(1)
+		tmax = tmaxNumerator * math.Inf(1)
+	}
+
+	if tmin > tmax {
+		tmin, tmax = tmax, tmin
+	}
+
+	return tmin, tmax
+}
+
+func max3(a, b, c float32) float32 {
+	if b > a {
+		a = b
+	}
+
+	if c > a {
+		a = c
+	}
+
+	return a
+}
+
+func min3(a, b, c float32) float32 {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestCube_LocalIntersect(t *testing.T) {
+	tests := []struct {
+		ray math.Ray
+		res []float32
+	}{
+		{math.NewRay(math.NewPoint(5, 0.5, 0), math.NewVector(-1, 0, 0)), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(-5, 0.5, 0), math.NewVector(1, 0, 0)), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(0.5, 5, 0), math.NewVector(0, -1, 0)), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(0.5, -5, 0), math.NewVector(0, 1, 0)), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(0.5, 0, 5), math.NewVector(0, 0, -1)), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(0.5, 0, -5), math.NewVector(0, 0, 1)), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(0, 0.5, 0), math.NewVector(0, 0, 1)), []float32{-1, 1}},
+
+		// misses
+		{math.NewRay(math.NewPoint(-2, 0, 0), math.NewVector(0.2673, 0.5345, 0.8018)), nil},
+		{math.NewRay(math.NewPoint(0, -2, 0), math.NewVector(0.8018, 0.2673, 0.5345)), nil},
+		{math.NewRay(math.NewPoint(0, 0, -2), math.NewVector(0.5345, 0.8018, 0.2673)), nil},
+		{math.NewRay(math.NewPoint(2, 0, 2), math.NewVector(0, 0, -1)), nil},
+		{math.NewRay(math.NewPoint(0, 2, 2), math.NewVector(0, -1, 0)), nil},
+		{math.NewRay(math.NewPoint(2, 2, 0), math.NewVector(-1, 0, 0)), nil},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCube()
+			xs := c.LocalIntersect(&tt.ray, nil)
+			assertTs(t, xs, tt.res)
+		})
+	}
+}
+
+func TestCube_LocalNormalAt(t *testing.T) {
+	tests := []struct {
+		point math.Vec4f
+		res   math.Vec4f
+	}{
+		{math.NewPoint(1, 0.5, -0.8), math.NewVector(1, 0, 0)},
+		{math.NewPoint(-1, -0.2, 0.9), math.NewVector(-1, 0, 0)},
+		{math.NewPoint(-0.4, 1, -0.1), math.NewVector(0, 1, 0)},
+		{math.NewPoint(0.3, -1, -0.7), math.NewVector(0, -1, 0)},
+		{math.NewPoint(-0.6, 0.3, 1), math.NewVector(0, 0, 1)},
+		{math.NewPoint(0.4, 0.4, -1), math.NewVector(0, 0, -1)},
+		{math.NewPoint(1, 1, 1), math.NewVector(1, 0, 0)},
+		{math.NewPoint(-1, -1, -1), math.NewVector(-1, 0, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCube()
+			if n := c.LocalNormalAt(&tt.point, nil); !n.Equals(&tt.res) {
+				t.Errorf("LocalNormalAt(%v) = %v, want %v", tt.point, n, tt.res)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Cylinder has a radius of 1 around the y axis. It is infinitely long, unless it is truncated by
+// Minimum and Maximum, which are both excluded. A truncated cylinder is hollow, unless it is Closed.
+type Cylinder struct {
+	baseShape
+	Minimum, Maximum float32
+	Closed           bool
+}
+
+// NewCylinder allocates an infinite cylinder with the identity transformation.
+func NewCylinder() *Cylinder {
+	return &Cylinder{
+		baseShape: newBaseShape(),
+		Minimum:   math.Inf(-1),
+		Maximum:   math.Inf(1),
+	}
+}
+
+// LocalIntersect appends the intersections with the walls and with the caps.
+func (c *Cylinder) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	a := ray.Direction.X*ray.Direction.X + ray.Direction.Z*ray.Direction.Z
+
+	// a ray parallel to the y axis can only hit the caps
+	if !math.Equalf(a, 0) {
+		b := 2*ray.Origin.X*ray.Direction.X + 2*ray.Origin.Z*ray.Direction.Z
+		cc := ray.Origin.X*ray.Origin.X + ray.Origin.Z*ray.Origin.Z - 1
+
+		disc := b*b - 4*a*cc
+		if disc < 0 {
+			return xs
+		}
+
+		xs = appendWalls(c, ray, xs, a, b, disc, c.Minimum, c.Maximum)
+	}
+
+	if c.Closed {
+		xs = intersectCap(c, ray, c.Minimum, 1, xs)
+		xs = intersectCap(c, ray, c.Maximum, 1, xs)
+	}
+
+	return xs
+}
+
+// appendWalls appends the solutions of the quadratic equation, whose y values are within min and max.
+func appendWalls(s Shape, ray *math.Ray, xs Intersections, a, b, disc, min, max float32) Intersections {
+	sqrtDisc := math.Sqrt(disc)
+	t0 := (-b - sqrtDisc) / (2 * a)
+	t1 := (-b + sqrtDisc) / (2 * a)
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+
+	y0 := ray.Origin.Y + t0*ray.Direction.Y
+	if min < y0 && y0 < max {
+		xs = append(xs, Intersection{T: t0, Object: s})
+	}
+
+	y1 := ray.Origin.Y + t1*ray.Direction.Y
+	if min < y1 && y1 < max {
+		xs = append(xs, Intersection{T: t1, Object: s})
+	}
+
+	return xs
+}
+
+// LocalNormalAt returns the normal of the wall or of a cap.
+func (c *Cylinder) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	dist := point.X*point.X + point.Z*point.Z
+
+	if dist < 1 && point.Y >= c.Maximum-math.Epsilon {
+		return math.NewVector(0, 1, 0)
+	}
+
+	if dist < 1 && point.Y <= c.Minimum+math.Epsilon {
+		return math.NewVector(0, -1, 0)
+	}
+
+	return math.NewVector(point.X, 0, point.Z)
+}
+
+// Bounds returns a box with a radius of 1, from Minimum to Maximum.
+func (c *Cylinder) Bounds() math.BoundingBox {
+	return math.NewBoundingBox(math.NewPoint(-1, c.Minimum, -1), math.NewPoint(1, c.Maximum, 1))
+}
+
+// intersectCap appends the intersection with the cap plane at y, if it is within the radius. The radius
+// is slightly enlarged, so that rays through the edge of the cap are not lost due to float32 precision.
+func intersectCap(s Shape, ray *math.Ray, y, radius float32, xs Intersections) Intersections {
+	if math.Equalf(ray.Direction.Y, 0) {
+		return xs
+	}
+
+	t := (y - ray.Origin.Y) / ray.Direction.Y
+	x := ray.Origin.X + t*ray.Direction.X
+	z := ray.Origin.Z + t*ray.Direction.Z
+	if x*x+z*z <= radius*radius+math.Epsilon {
+		xs = append(xs, Intersection{T: t, Object: s})
+	}
+
+	return xs
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func normalized(v math.Vec4f) math.Vec4f {
+	v.Normalize()
+	return v
+}
+
+func TestCylinder_LocalIntersect(t *testing.T) {
+	tests := []struct {
+		min, max  float32
+		closed    bool
+		origin    math.Vec4f
+		direction math.Vec4f
+		res       []float32
+	}{
+		// misses
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(1, 0, 0), math.NewVector(0, 1, 0), nil},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 0, 0), math.NewVector(0, 1, 0), nil},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 0, -5), math.NewVector(1, 1, 1), nil},
+
+		// hits
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(1, 0, -5), math.NewVector(0, 0, 1), []float32{5, 5}},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1), []float32{4, 6}},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0.5, 0, -5), math.NewVector(0.1, 1, 1), []float32{6.80798, 7.08872}},
+
+		// truncated
+		{1, 2, false, math.NewPoint(0, 1.5, 0), math.NewVector(0.1, 1, 0), nil},
+		{1, 2, false, math.NewPoint(0, 3, -5), math.NewVector(0, 0, 1), nil},
+		{1, 2, false, math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1), nil},
+		{1, 2, false, math.NewPoint(0, 2, -5), math.NewVector(0, 0, 1), nil},
+		{1, 2, false, math.NewPoint(0, 1, -5), math.NewVector(0, 0, 1), nil},
+		{1, 2, false, math.NewPoint(0, 1.5, -2), math.NewVector(0, 0, 1), []float32{1, 3}},
+
+		// closed caps
+		{1, 2, true, math.NewPoint(0, 3, 0), math.NewVector(0, -1, 0), []float32{2, 1}},
+		{1, 2, true, math.NewPoint(0, 3, -2), math.NewVector(0, -1, 2), []float32{3.35410, 2.23607}},
+		// corner cases, where the ray leaves through the edge of the cap
+		{1, 2, true, math.NewPoint(0, 4, -2), math.NewVector(0, -1, 1), []float32{4.24264, 2.82843}},
+		{1, 2, true, math.NewPoint(0, 0, -2), math.NewVector(0, 1, 2), []float32{3.35410, 2.23607}},
+		{1, 2, true, math.NewPoint(0, -1, -2), math.NewVector(0, 1, 1), []float32{2.82843, 4.24264}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCylinder()
+			c.Minimum = tt.min
+			c.Maximum = tt.max
+			c.Closed = tt.closed
+			r := math.NewRay(tt.origin, normalized(tt.direction))
+			xs := c.LocalIntersect(&r, nil)
+			assertTs(t, xs, tt.res)
+		})
+	}
+}
+
+func TestCylinder_LocalNormalAt(t *testing.T) {
+	tests := []struct {
+		min, max float32
+		closed   bool
+		point    math.Vec4f
+		res      math.Vec4f
+	}{
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(1, 0, 0), math.NewVector(1, 0, 0)},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, 5, -1), math.NewVector(0, 0, -1)},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(0, -2, 1), math.NewVector(0, 0, 1)},
+		{math.Inf(-1), math.Inf(1), false, math.NewPoint(-1, 1, 0), math.NewVector(-1, 0, 0)},
+		{1, 2, true, math.NewPoint(0, 1, 0), math.NewVector(0, -1, 0)},
+		{1, 2, true, math.NewPoint(0.5, 1, 0), math.NewVector(0, -1, 0)},
+		{1, 2, true, math.NewPoint(0, 1, 0.5), math.NewVector(0, -1, 0)},
+		{1, 2, true, math.NewPoint(0, 2, 0), math.NewVector(0, 1, 0)},
+		{1, 2, true, math.NewPoint(0.5, 2, 0), math.NewVector(0, 1, 0)},
+		{1, 2, true, math.NewPoint(0, 2, 0.5), math.NewVector(0, 1, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := NewCylinder()
+			c.Minimum = tt.min
+			c.Maximum = tt.max
+			c.Closed = tt.closed
+			if n := c.LocalNormalAt(&tt.point, nil); !n.Equals(&tt.res) {
+				t.Errorf("LocalNormalAt(%v) = %v, want %v", tt.point, n, tt.res)
+			}
+		})
+	}
+}
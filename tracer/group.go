@@ -0,0 +1,175 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"sort"
+)
+
+// A Group is a container of child shapes, which are transformed by the transformation of the group.
+// Groups can be nested to build a scene graph. The bounds of the children are cached and updated, whenever
+// a child is added or a shape within the group is transformed. Other modifications of a shape, which change
+// its bounds (like Cylinder.Maximum), must be done before it is added.
+type Group struct {
+	baseShape
+	children    []Shape
+	childBounds []math.BoundingBox // the parent space bounds at the same index as children
+	bounds      math.BoundingBox
+}
+
+// NewGroup allocates an empty group with the identity transformation.
+func NewGroup() *Group {
+	return &Group{baseShape: newBaseShape(), bounds: math.EmptyBoundingBox()}
+}
+
+// AddChild appends the shapes and makes the group their parent. A shape must only be added to a single
+// group, because the parent is unique. To place an assembly many times, create it by a function and
+// add each created instance to a group with its own placement transformation.
+func (g *Group) AddChild(children ...Shape) {
+	for _, c := range children {
+		c.SetParent(g)
+		b := ParentSpaceBounds(c)
+		g.children = append(g.children, c)
+		g.childBounds = append(g.childBounds, b)
+		g.bounds.Merge(&b)
+	}
+
+	boundsChanged(g.parent)
+}
+
+// Children returns the child shapes. The slice must not be modified.
+func (g *Group) Children() []Shape {
+	return g.children
+}
+
+// Bounds returns the cached box around all children.
+func (g *Group) Bounds() math.BoundingBox {
+	return g.bounds
+}
+
+// LocalIntersect intersects the ray with each child, whose bounds are hit, and appends the intersections
+// sorted by t.
+func (g *Group) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	start := len(xs)
+	for i, c := range g.children {
+		if g.childBounds[i].Intersects(ray) {
+			xs = Intersect(c, ray, xs)
+		}
+	}
+
+	sort.Sort(xs[start:])
+	return xs
+}
+
+// LocalNormalAt must not be called, because a group has no surface. The normals are always calculated
+// by the children, which have been hit.
+func (g *Group) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	panic("tracer: a group has no normal")
+}
+
+// Divide builds a bounding volume hierarchy, by recursively splitting each group with at least threshold
+// children into two subgroups, which contain the children of the respective half of the bounds. Children
+// which do not fit into either half, like planes, stay in the group. Groups nested in CSGs are divided as well.
+func (g *Group) Divide(threshold int) {
+	if threshold <= len(g.children) {
+		left, right := g.partition()
+		if len(left) > 0 {
+			g.addSubgroup(left)
+		}
+
+		if len(right) > 0 {
+			g.addSubgroup(right)
+		}
+	}
+
+	for _, c := range g.children {
+		divide(c, threshold)
+	}
+}
+
+// partition removes and returns the children, which fit completely into the left or right half of the bounds.
+// Only the finite bounds are split, so unbounded children, like planes, never fit and stay in the group.
+func (g *Group) partition() (left, right []Shape) {
+	finite := math.EmptyBoundingBox()
+	for i := range g.childBounds {
+		if isFinite(&g.childBounds[i]) {
+			finite.Merge(&g.childBounds[i])
+		}
+	}
+
+	if finite.IsEmpty() {
+		return nil, nil
+	}
+
+	leftBounds, rightBounds := finite.Split()
+	var remaining []Shape
+	for i, c := range g.children {
+		switch {
+		case leftBounds.ContainsBox(&g.childBounds[i]):
+			left = append(left, c)
+		case rightBounds.ContainsBox(&g.childBounds[i]):
+			right = append(right, c)
+		default:
+			remaining = append(remaining, c)
+		}
+	}
+
+	if len(left) == len(g.children) || len(right) == len(g.children) {
+		// degenerated, e.g. all children have the same flat bounds, which would split forever
+		return nil, nil
+	}
+
+	g.children = remaining
+	g.recalculateBounds()
+	return left, right
+}
+
+// addSubgroup moves the children into a new group, without changing the overall bounds.
+func (g *Group) addSubgroup(children []Shape) {
+	sub := NewGroup()
+	sub.AddChild(children...) // does not notify g, because sub has no parent yet
+	sub.SetParent(g)
+	b := ParentSpaceBounds(sub)
+	g.children = append(g.children, sub)
+	g.childBounds = append(g.childBounds, b)
+	g.bounds.Merge(&b)
+}
+
+// updateBounds recalculates the cached bounds and notifies the parent.
+func (g *Group) updateBounds() {
+	g.recalculateBounds()
+	boundsChanged(g.parent)
+}
+
+func (g *Group) recalculateBounds() {
+	g.childBounds = g.childBounds[:0]
+	g.bounds = math.EmptyBoundingBox()
+	for _, c := range g.children {
+		b := ParentSpaceBounds(c)
+		g.childBounds = append(g.childBounds, b)
+		g.bounds.Merge(&b)
+	}
+}
+
+// divide builds the bounding volume hierarchy of groups and of groups nested in CSGs.
+func divide(s Shape, threshold int) {
+	switch s := s.(type) {
+	case *Group:
+		s.Divide(threshold)
+	case *CSG:
+		s.Divide(threshold)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestGroup_AddChild(t *testing.T) {
+	g := NewGroup()
+	if len(g.Children()) != 0 {
+		t.Errorf("len(Children()) = %v, want 0", len(g.Children()))
+	}
+
+	s := newTestShape()
+	g.AddChild(s)
+	if len(g.Children()) != 1 || g.Children()[0] != s {
+		t.Errorf("Children() = %v, want [%v]", g.Children(), s)
+	}
+
+	if s.Parent() != g {
+		t.Errorf("Parent() = %v, want %v", s.Parent(), g)
+	}
+}
+
+func TestGroup_LocalIntersect(t *testing.T) {
+	// an empty group
+	g := NewGroup()
+	r := math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 0, 1))
+	if xs := g.LocalIntersect(&r, nil); len(xs) != 0 {
+		t.Errorf("len(xs) = %v, want 0", len(xs))
+	}
+
+	// a group with children
+	s1 := NewSphere()
+	s2 := NewSphere()
+	s2.SetTransform(math.Translation(0, 0, -3))
+	s3 := NewSphere()
+	s3.SetTransform(math.Translation(5, 0, 0))
+	g.AddChild(s1, s2, s3)
+
+	r = math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+	xs := g.LocalIntersect(&r, nil)
+	want := []Shape{s2, s2, s1, s1}
+	if len(xs) != len(want) {
+		t.Fatalf("len(xs) = %v, want %v", len(xs), len(want))
+	}
+
+	for i := range want {
+		if xs[i].Object != want[i] {
+			t.Errorf("xs[%d].Object = %v, want %v", i, xs[i].Object, want[i])
+		}
+	}
+
+	// a transformed group
+	g = NewGroup()
+	g.SetTransform(math.Scaling(2, 2, 2))
+	s := NewSphere()
+	s.SetTransform(math.Translation(5, 0, 0))
+	g.AddChild(s)
+
+	r = math.NewRay(math.NewPoint(10, 0, -10), math.NewVector(0, 0, 1))
+	if xs := Intersect(g, &r, nil); len(xs) != 2 {
+		t.Errorf("len(xs) = %v, want 2", len(xs))
+	}
+}
+
+func TestWorldToObject(t *testing.T) {
+	g1 := NewGroup()
+	g1.SetTransform(math.RotationY(math.Pi / 2))
+	g2 := NewGroup()
+	g2.SetTransform(math.Scaling(2, 2, 2))
+	g1.AddChild(g2)
+	s := NewSphere()
+	s.SetTransform(math.Translation(5, 0, 0))
+	g2.AddChild(s)
+
+	p := math.NewPoint(-2, 0, -10)
+	got := WorldToObject(s, &p)
+	want := math.NewPoint(0, 0, -1)
+	if !equalsApprox(&got, &want) {
+		t.Errorf("WorldToObject() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalToWorld(t *testing.T) {
+	g1 := NewGroup()
+	g1.SetTransform(math.RotationY(math.Pi / 2))
+	g2 := NewGroup()
+	g2.SetTransform(math.Scaling(1, 2, 3))
+	g1.AddChild(g2)
+	s := NewSphere()
+	s.SetTransform(math.Translation(5, 0, 0))
+	g2.AddChild(s)
+
+	sqrt3 := math.Sqrt(3) / 3
+	n := math.NewVector(sqrt3, sqrt3, sqrt3)
+	got := NormalToWorld(s, &n)
+	want := math.NewVector(0.2857, 0.4286, -0.8571)
+	if !equalsApprox(&got, &want) {
+		t.Errorf("NormalToWorld() = %v, want %v", got, want)
+	}
+
+	// the normal of a child object
+	p := math.NewPoint(1.7321, 1.1547, -5.5774)
+	got = NormalAt(s, &p, nil)
+	want = math.NewVector(0.2857, 0.4286, -0.8571)
+	if !equalsApprox(&got, &want) {
+		t.Errorf("NormalAt() = %v, want %v", got, want)
+	}
+}
+
+func TestGroup_Bounds(t *testing.T) {
+	s := NewSphere()
+	s.SetTransform(math.Scaling(2, 2, 2).Translate(2, 5, -3))
+	c := NewCylinder()
+	c.Minimum = -2
+	c.Maximum = 2
+	c.SetTransform(math.Scaling(0.5, 1, 0.5).Translate(-4, -1, 4))
+	g := NewGroup()
+	g.AddChild(s, c)
+
+	want := math.NewBoundingBox(math.NewPoint(-4.5, -3, -5), math.NewPoint(4, 7, 4.5))
+	if got := g.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	// transforming a nested child updates the bounds of all ancestors
+	outer := NewGroup()
+	outer.AddChild(g)
+	s.SetTransform(math.Translation(10, 0, 0))
+	want = math.NewBoundingBox(math.NewPoint(-4.5, -3, -1), math.NewPoint(11, 1, 4.5))
+	if got := g.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	if got := outer.Bounds(); !equalsBounds(got, want) {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+}
+
+func TestGroup_LocalIntersectBounds(t *testing.T) {
+	tests := []struct {
+		ray math.Ray
+		hit bool
+	}{
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 1, 0)), false},
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)), true},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			child := newTestShape()
+			g := NewGroup()
+			g.AddChild(child)
+			g.LocalIntersect(&tt.ray, nil)
+
+			// the test shape records the ray only if it has not been skipped
+			if hit := child.savedRay != (math.Ray{}); hit != tt.hit {
+				t.Errorf("intersected = %v, want %v", hit, tt.hit)
+			}
+		})
+	}
+}
+
+func TestGroup_Divide(t *testing.T) {
+	// partitions the children
+	s1 := NewSphere()
+	s1.SetTransform(math.Translation(-2, -2, 0))
+	s2 := NewSphere()
+	s2.SetTransform(math.Translation(-2, 2, 0))
+	s3 := NewSphere()
+	s3.SetTransform(math.Scaling(4, 4, 4))
+	g := NewGroup()
+	g.AddChild(s1, s2, s3)
+	bounds := g.Bounds()
+	g.Divide(1)
+
+	if len(g.Children()) != 2 || g.Children()[0] != s3 {
+		t.Fatalf("Children() = %v, want [%v subgroup]", g.Children(), s3)
+	}
+
+	sub := g.Children()[1].(*Group)
+	if len(sub.Children()) != 2 {
+		t.Fatalf("len(Children()) = %v, want 2", len(sub.Children()))
+	}
+
+	for i, want := range []Shape{s1, s2} {
+		leaf := sub.Children()[i].(*Group)
+		if len(leaf.Children()) != 1 || leaf.Children()[0] != want {
+			t.Errorf("Children() = %v, want [%v]", leaf.Children(), want)
+		}
+
+		if want.Parent() != leaf {
+			t.Errorf("Parent() = %v, want %v", want.Parent(), leaf)
+		}
+	}
+
+	if got := g.Bounds(); !equalsBounds(got, bounds) {
+		t.Errorf("Bounds() = %v, want %v", got, bounds)
+	}
+
+	// too few children are not partitioned, but the subgroups are
+	s1 = NewSphere()
+	s1.SetTransform(math.Translation(-2, 0, 0))
+	s2 = NewSphere()
+	s2.SetTransform(math.Translation(2, 1, 0))
+	s3 = NewSphere()
+	s3.SetTransform(math.Translation(2, -1, 0))
+	sub = NewGroup()
+	sub.AddChild(s1, s2, s3)
+	s4 := NewSphere()
+	g = NewGroup()
+	g.AddChild(sub, s4)
+	g.Divide(3)
+
+	if len(g.Children()) != 2 || g.Children()[0] != sub || g.Children()[1] != s4 {
+		t.Fatalf("Children() = %v, want [%v %v]", g.Children(), sub, s4)
+	}
+
+	if len(sub.Children()) != 2 {
+		t.Fatalf("len(Children()) = %v, want 2", len(sub.Children()))
+	}
+
+	left := sub.Children()[0].(*Group)
+	right := sub.Children()[1].(*Group)
+	if len(left.Children()) != 1 || left.Children()[0] != s1 {
+		t.Errorf("Children() = %v, want [%v]", left.Children(), s1)
+	}
+
+	if len(right.Children()) != 2 || right.Children()[0] != s2 || right.Children()[1] != s3 {
+		t.Errorf("Children() = %v, want [%v %v]", right.Children(), s2, s3)
+	}
+
+	// children with equal flat bounds can never be separated
+	g = NewGroup()
+	for i := 0; i < 3; i++ {
+		g.AddChild(NewTriangle(math.NewPoint(0, 1, 0), math.NewPoint(-1, 0, 0), math.NewPoint(1, 0, 0)))
+	}
+
+	g.Divide(1)
+	if len(g.Children()) != 3 {
+		t.Errorf("len(Children()) = %v, want 3", len(g.Children()))
+	}
+
+	// the hierarchy must not change the result
+	r := math.NewRay(math.NewPoint(0, 0.5, -5), math.NewVector(0, 0, 1))
+	if xs := Intersect(g, &r, nil); len(xs) != 3 {
+		t.Errorf("len(xs) = %v, want 3", len(xs))
+	}
+
+	// an unbounded plane stays in the group and does not prevent the partitioning of the others
+	plane := NewPlane()
+	g = NewGroup()
+	g.AddChild(plane)
+	for i := 0; i < 8; i++ {
+		s := NewSphere()
+		s.SetTransform(math.Translation(float32(i*3), 0, 0))
+		g.AddChild(s)
+	}
+
+	g.Divide(2)
+	if len(g.Children()) != 3 || g.Children()[0] != plane {
+		t.Fatalf("Children() = %v, want [%v subgroup subgroup]", g.Children(), plane)
+	}
+
+	for _, c := range g.Children()[1:] {
+		if sub, ok := c.(*Group); !ok || len(sub.Children()) != 2 {
+			t.Errorf("Children() = %v, want a subgroup with 2 children", c)
+		}
+	}
+}
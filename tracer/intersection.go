@@ -0,0 +1,175 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// An Intersection records the distance t along a ray, at which the object has been hit.
+type Intersection struct {
+	T      float32
+	Object Shape
+	U, V   float32 // barycentric coordinates of the hit, only used by triangles
+}
+
+// Intersections is a collection of Intersection values. Intersect methods append to it, so that
+// a single slice can be reused for many rays.
+type Intersections []Intersection
+
+// Hit returns the visible intersection, which is the one with the lowest non-negative t. Intersections
+// with a negative t are behind the ray origin. Returns nil, if nothing is visible.
+func (xs Intersections) Hit() *Intersection {
+	var hit *Intersection
+	for i := range xs {
+		if xs[i].T < 0 {
+			continue
+		}
+
+		if hit == nil || xs[i].T < hit.T {
+			hit = &xs[i]
+		}
+	}
+
+	return hit
+}
+
+// Len returns the number of intersections.
+func (xs Intersections) Len() int {
+	return len(xs)
+}
+
+// Less sorts ascending by t.
+func (xs Intersections) Less(i, j int) bool {
+	return xs[i].T < xs[j].T
+}
+
+// Swap exchanges the intersections.
+func (xs Intersections) Swap(i, j int) {
+	xs[i], xs[j] = xs[j], xs[i]
+}
+
+// overPointBias is the distance, by which the OverPoint is moved along the normal. It is derived from
+// math.Epsilon but larger, because float32 intersections are not precise enough and a bias of
+// just math.Epsilon still lets the surface shadow itself (acne).
+const overPointBias = math.Epsilon * 100
+
+// Computations contains precomputed values of an intersection, which are used to shade the hit.
+type Computations struct {
+	T          float32
+	Object     Shape
+	Point      math.Vec4f // the intersection point in world space
+	OverPoint  math.Vec4f // the intersection point slightly above the surface, to avoid self shadowing
+	UnderPoint math.Vec4f // the intersection point slightly below the surface, where refracted rays start
+	EyeV       math.Vec4f // points from the intersection to the eye
+	NormalV    math.Vec4f // the surface normal, which always points to the eye
+	ReflectV   math.Vec4f // the reflection of the ray direction around the normal
+	Inside     bool       // true, if the ray origin is inside the object and the normal has been inverted
+	N1, N2     float32    // refractive indices of the materials the ray is leaving and entering
+}
+
+// PrepareComputations calculates the shading values for the given intersection of the ray. Xs are all
+// intersections of the ray, sorted by t, which are required to find out which objects contain the hit and
+// therefore the refractive indices on both sides of the surface. Xs may be nil, if no object is transparent.
+func PrepareComputations(hit *Intersection, r *math.Ray, xs Intersections) Computations {
+	comps := Computations{
+		T:      hit.T,
+		Object: hit.Object,
+		Point:  r.Position(hit.T),
+		EyeV:   r.Direction,
+		N1:     1,
+		N2:     1,
+	}
+
+	comps.EyeV.Negate()
+	comps.NormalV = NormalAt(comps.Object, &comps.Point, hit)
+
+	if comps.NormalV.Dot(&comps.EyeV) < 0 {
+		comps.Inside = true
+		comps.NormalV.Negate()
+	}
+
+	comps.ReflectV = r.Direction
+	comps.ReflectV.Reflect(&comps.NormalV)
+
+	offset := comps.NormalV
+	offset.Mul(overPointBias)
+	comps.OverPoint = comps.Point
+	comps.OverPoint.Add(&offset)
+	comps.UnderPoint = comps.Point
+	comps.UnderPoint.Sub(&offset)
+
+	comps.N1, comps.N2 = refractiveIndices(hit, xs)
+
+	return comps
+}
+
+// refractiveIndices walks along the intersections and keeps track of the objects which contain the
+// current intersection. When the hit is reached, n1 is the index of the innermost object, which
+// the ray leaves, and n2 is the index of the object, which the ray enters afterwards.
+func refractiveIndices(hit *Intersection, xs Intersections) (n1, n2 float32) {
+	n1, n2 = 1, 1
+	var containers []Shape
+	for _, x := range xs {
+		isHit := x == *hit
+		if isHit && len(containers) > 0 {
+			n1 = containers[len(containers)-1].Material().RefractiveIndex
+		}
+
+		// the ray either leaves or enters the object
+		found := false
+		for i, c := range containers {
+			if c == x.Object {
+				containers = append(containers[:i], containers[i+1:]...)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			containers = append(containers, x.Object)
+		}
+
+		if isHit {
+			if len(containers) > 0 {
+				n2 = containers[len(containers)-1].Material().RefractiveIndex
+			}
+
+			break
+		}
+	}
+
+	return n1, n2
+}
+
+// Schlick approximates the Fresnel effect, which is the fraction of the light that is reflected
+// at the surface instead of being refracted.
+func Schlick(comps *Computations) float32 {
+	cos := comps.EyeV.Dot(&comps.NormalV)
+
+	// total internal reflection can only occur if n1 > n2
+	if comps.N1 > comps.N2 {
+		n := comps.N1 / comps.N2
+		sin2t := n * n * (1 - cos*cos)
+		if sin2t > 1 {
+			return 1
+		}
+
+		// when n1 > n2, use cos(theta_t) instead
+		cos = math.Sqrt(1 - sin2t)
+	}
+
+	r0 := (comps.N1 - comps.N2) / (comps.N1 + comps.N2)
+	r0 = r0 * r0
+	return r0 + (1-r0)*math.Pow(1-cos, 5)
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestIntersections_Hit(t *testing.T) {
+	s := NewSphere()
+	tests := []struct {
+		xs   Intersections
+		want int // index into xs or -1 for no hit
+	}{
+		{Intersections{{T: 1, Object: s}, {T: 2, Object: s}}, 0},
+		{Intersections{{T: -1, Object: s}, {T: 1, Object: s}}, 1},
+		{Intersections{{T: -2, Object: s}, {T: -1, Object: s}}, -1},
+		{Intersections{{T: 5, Object: s}, {T: 7, Object: s}, {T: -3, Object: s}, {T: 2, Object: s}}, 3},
+		{nil, -1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			hit := tt.xs.Hit()
+			if tt.want == -1 {
+				if hit != nil {
+					t.Errorf("Hit() = %v, want nil", hit)
+				}
+
+				return
+			}
+
+			if hit != &tt.xs[tt.want] {
+				t.Errorf("Hit() = %v, want %v", hit, tt.xs[tt.want])
+			}
+		})
+	}
+}
+
+func TestPrepareComputations(t *testing.T) {
+	tests := []struct {
+		ray     math.Ray
+		t       float32
+		point   math.Vec4f
+		eyev    math.Vec4f
+		normalv math.Vec4f
+		inside  bool
+	}{
+		{
+			math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)), 4,
+			math.NewPoint(0, 0, -1), math.NewVector(0, 0, -1), math.NewVector(0, 0, -1), false,
+		},
+		{
+			math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 0, 1)), 1,
+			math.NewPoint(0, 0, 1), math.NewVector(0, 0, -1), math.NewVector(0, 0, -1), true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s := NewSphere()
+			hit := Intersection{T: tt.t, Object: s}
+			comps := PrepareComputations(&hit, &tt.ray, nil)
+			if comps.T != tt.t || comps.Object != s {
+				t.Errorf("unexpected intersection %v", comps)
+			}
+
+			if !comps.Point.Equals(&tt.point) {
+				t.Errorf("Point = %v, want %v", comps.Point, tt.point)
+			}
+
+			if !comps.EyeV.Equals(&tt.eyev) {
+				t.Errorf("EyeV = %v, want %v", comps.EyeV, tt.eyev)
+			}
+
+			if !comps.NormalV.Equals(&tt.normalv) {
+				t.Errorf("NormalV = %v, want %v", comps.NormalV, tt.normalv)
+			}
+
+			if comps.Inside != tt.inside {
+				t.Errorf("Inside = %v, want %v", comps.Inside, tt.inside)
+			}
+		})
+	}
+}
+
+func TestPrepareComputations_OverPoint(t *testing.T) {
+	r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+	s := NewSphere()
+	s.SetTransform(math.Translation(0, 0, 1))
+	hit := Intersection{T: 5, Object: s}
+	comps := PrepareComputations(&hit, &r, nil)
+	if comps.OverPoint.Z >= -math.Epsilon/2 {
+		t.Errorf("OverPoint.Z = %v, want < %v", comps.OverPoint.Z, -math.Epsilon/2)
+	}
+
+	if comps.Point.Z <= comps.OverPoint.Z {
+		t.Errorf("Point.Z = %v, want > %v", comps.Point.Z, comps.OverPoint.Z)
+	}
+}
+
+// newGlassSphere returns a sphere with a transparent glass like material.
+func newGlassSphere() *Sphere {
+	s := NewSphere()
+	s.Material().Transparency = 1
+	s.Material().RefractiveIndex = 1.5
+	return s
+}
+
+func TestPrepareComputations_RefractiveIndices(t *testing.T) {
+	a := newGlassSphere()
+	a.SetTransform(math.Scaling(2, 2, 2))
+	a.Material().RefractiveIndex = 1.5
+
+	b := newGlassSphere()
+	b.SetTransform(math.Translation(0, 0, -0.25))
+	b.Material().RefractiveIndex = 2
+
+	c := newGlassSphere()
+	c.SetTransform(math.Translation(0, 0, 0.25))
+	c.Material().RefractiveIndex = 2.5
+
+	r := math.NewRay(math.NewPoint(0, 0, -4), math.NewVector(0, 0, 1))
+	xs := Intersections{{T: 2, Object: a}, {T: 2.75, Object: b}, {T: 3.25, Object: c}, {T: 4.75, Object: b}, {T: 5.25, Object: c}, {T: 6, Object: a}}
+	tests := []struct {
+		n1, n2 float32
+	}{
+		{1, 1.5},
+		{1.5, 2},
+		{2, 2.5},
+		{2.5, 2.5},
+		{2.5, 1.5},
+		{1.5, 1},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			comps := PrepareComputations(&xs[i], &r, xs)
+			if comps.N1 != tt.n1 || comps.N2 != tt.n2 {
+				t.Errorf("N1, N2 = %v, %v, want %v, %v", comps.N1, comps.N2, tt.n1, tt.n2)
+			}
+		})
+	}
+}
+
+func TestPrepareComputations_UnderPoint(t *testing.T) {
+	r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+	s := newGlassSphere()
+	s.SetTransform(math.Translation(0, 0, 1))
+	xs := Intersections{{T: 5, Object: s}}
+	comps := PrepareComputations(&xs[0], &r, xs)
+	if comps.UnderPoint.Z <= math.Epsilon/2 {
+		t.Errorf("UnderPoint.Z = %v, want > %v", comps.UnderPoint.Z, math.Epsilon/2)
+	}
+
+	if comps.Point.Z >= comps.UnderPoint.Z {
+		t.Errorf("Point.Z = %v, want < %v", comps.Point.Z, comps.UnderPoint.Z)
+	}
+}
+
+func TestPrepareComputations_ReflectV(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	p := NewPlane()
+	r := math.NewRay(math.NewPoint(0, 1, -1), math.NewVector(0, -sqrt2, sqrt2))
+	hit := Intersection{T: math.Sqrt(2), Object: p}
+	comps := PrepareComputations(&hit, &r, nil)
+	want := math.NewVector(0, sqrt2, sqrt2)
+	if !comps.ReflectV.Equals(&want) {
+		t.Errorf("ReflectV = %v, want %v", comps.ReflectV, want)
+	}
+}
+
+func TestSchlick(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		ray math.Ray
+		ts  []float32
+		hit int
+		res float32
+	}{
+		// total internal reflection
+		{math.NewRay(math.NewPoint(0, 0, sqrt2), math.NewVector(0, 1, 0)), []float32{-sqrt2, sqrt2}, 1, 1},
+		// perpendicular
+		{math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 1, 0)), []float32{-1, 1}, 1, 0.04},
+		// small angle and n2 > n1
+		{math.NewRay(math.NewPoint(0, 0.99, -2), math.NewVector(0, 0, 1)), []float32{1.8589}, 0, 0.48873},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s := newGlassSphere()
+			var xs Intersections
+			for _, v := range tt.ts {
+				xs = append(xs, Intersection{T: v, Object: s})
+			}
+
+			comps := PrepareComputations(&xs[tt.hit], &tt.ray, xs)
+			if got := Schlick(&comps); math.Abs(got-tt.res) > approxEpsilon {
+				t.Errorf("Schlick() = %v, want %v", got, tt.res)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A PointLight is a light source without a size, which radiates into all directions.
+type PointLight struct {
+	Position  math.Vec4f // a point
+	Intensity math.Vec4f // the color of the light
+}
+
+// NewPointLight creates a light at the given point with the given color.
+func NewPointLight(position, intensity math.Vec4f) PointLight {
+	return PointLight{Position: position, Intensity: intensity}
+}
+
+// Lighting calculates the color of the point on the surface of the object using the Phong reflection model.
+// The object is only required to evaluate the pattern of the material. Eyev points from the point to
+// the eye and normalv is the surface normal, both must be normalized. If the point is in shadow, only the
+// ambient part contributes. The returned color is always opaque.
+func Lighting(m *Material, object Shape, light *PointLight, point, eyev, normalv *math.Vec4f, inShadow bool) math.Vec4f {
+	// combine surface color and light color
+	effectiveColor := m.Color
+	if m.Pattern != nil {
+		effectiveColor = PatternAtShape(m.Pattern, object, point)
+	}
+
+	effectiveColor.MulVec(&light.Intensity)
+
+	// direction to the light source
+	lightv := light.Position
+	lightv.Sub(point)
+	lightv.Normalize()
+
+	ambient := effectiveColor
+	ambient.Mul(m.Ambient)
+	if inShadow {
+		ambient.W = 1
+		return ambient
+	}
+
+	var diffuse, specular math.Vec4f
+
+	// a negative cosine means, that the light is on the other side of the surface
+	lightDotNormal := lightv.Dot(normalv)
+	if lightDotNormal >= 0 {
+		diffuse = effectiveColor
+		diffuse.Mul(m.Diffuse * lightDotNormal)
+
+		// a negative cosine means, that the light reflects away from the eye
+		reflectv := lightv
+		reflectv.Negate()
+		reflectv.Reflect(normalv)
+		reflectDotEye := reflectv.Dot(eyev)
+		if reflectDotEye > 0 {
+			factor := math.Pow(reflectDotEye, m.Shininess)
+			specular = light.Intensity
+			specular.Mul(m.Specular * factor)
+		}
+	}
+
+	res := ambient
+	res.Add(&diffuse)
+	res.Add(&specular)
+	res.W = 1
+
+	return res
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestLighting(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		eyev     math.Vec4f
+		light    PointLight
+		inShadow bool
+		res      math.Vec4f
+	}{
+		// eye between light and surface
+		{math.NewVector(0, 0, -1), NewPointLight(math.NewPoint(0, 0, -10), math.NewRGB(1, 1, 1)), false, math.NewRGB(1.9, 1.9, 1.9)},
+		// eye offset by 45°
+		{math.NewVector(0, sqrt2, -sqrt2), NewPointLight(math.NewPoint(0, 0, -10), math.NewRGB(1, 1, 1)), false, math.NewRGB(1.0, 1.0, 1.0)},
+		// light offset by 45°
+		{math.NewVector(0, 0, -1), NewPointLight(math.NewPoint(0, 10, -10), math.NewRGB(1, 1, 1)), false, math.NewRGB(0.7364, 0.7364, 0.7364)},
+		// eye in the path of the reflection vector
+		{math.NewVector(0, -sqrt2, -sqrt2), NewPointLight(math.NewPoint(0, 10, -10), math.NewRGB(1, 1, 1)), false, math.NewRGB(1.6364, 1.6364, 1.6364)},
+		// light behind the surface
+		{math.NewVector(0, 0, -1), NewPointLight(math.NewPoint(0, 0, 10), math.NewRGB(1, 1, 1)), false, math.NewRGB(0.1, 0.1, 0.1)},
+		// surface in shadow
+		{math.NewVector(0, 0, -1), NewPointLight(math.NewPoint(0, 0, -10), math.NewRGB(1, 1, 1)), true, math.NewRGB(0.1, 0.1, 0.1)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			m := NewMaterial()
+			position := math.NewPoint(0, 0, 0)
+			normalv := math.NewVector(0, 0, -1)
+			res := Lighting(&m, NewSphere(), &tt.light, &position, &tt.eyev, &normalv, tt.inShadow)
+			if !equalsApprox(&res, &tt.res) {
+				t.Errorf("Lighting() = %v, want %v", res, tt.res)
+			}
+		})
+	}
+}
+
+func TestLighting_Pattern(t *testing.T) {
+	m := NewMaterial()
+	m.Pattern = NewStripePattern(NewSolidPattern(math.NewRGB(1, 1, 1)), NewSolidPattern(math.NewRGB(0, 0, 0)))
+	m.Ambient = 1
+	m.Diffuse = 0
+	m.Specular = 0
+
+	eyev := math.NewVector(0, 0, -1)
+	normalv := math.NewVector(0, 0, -1)
+	light := NewPointLight(math.NewPoint(0, 0, -10), math.NewRGB(1, 1, 1))
+	tests := []struct {
+		point math.Vec4f
+		res   math.Vec4f
+	}{
+		{math.NewPoint(0.9, 0, 0), math.NewRGB(1, 1, 1)},
+		{math.NewPoint(1.1, 0, 0), math.NewRGB(0, 0, 0)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := Lighting(&m, NewSphere(), &light, &tt.point, &eyev, &normalv, false)
+			if !res.Equals(&tt.res) {
+				t.Errorf("Lighting() = %v, want %v", res, tt.res)
+			}
+		})
+	}
+}
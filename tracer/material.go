@@ -0,0 +1,44 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Material describes the surface attributes of the Phong reflection model.
+type Material struct {
+	Color     math.Vec4f // the surface color, if no pattern has been set
+	Pattern   Pattern    // optional, used instead of the flat color
+	Ambient   float32    // background light or light reflected from other objects, usually between 0 and 1
+	Diffuse   float32    // light reflected from a matte surface, usually between 0 and 1
+	Specular  float32    // the bright spot, usually between 0 and 1
+	Shininess float32    // the larger, the smaller and tighter the specular highlight, usually between 10 and 200
+
+	Reflective      float32 // 0 means not reflective and 1 is a perfect mirror
+	Transparency    float32 // 0 means opaque and 1 is perfectly transparent
+	RefractiveIndex float32 // how much light bends when entering the material, e.g. 1 for vacuum and 1.5 for glass
+}
+
+// NewMaterial returns a white material with reasonable default values.
+func NewMaterial() Material {
+	return Material{
+		Color:     math.NewRGB(1, 1, 1),
+		Ambient:   0.1,
+		Diffuse:   0.9,
+		Specular:  0.9,
+		Shininess: 200,
+
+		RefractiveIndex: 1,
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Pattern calculates a color for each point in its pattern space. Like a Shape, each pattern has its
+// own transformation, which is relative to the object space of the shape or to the pattern space of
+// the pattern which contains it. Patterns nest other patterns instead of plain colors, see SolidPattern.
+type Pattern interface {
+	// Transform returns the pattern to object (or parent pattern) transformation.
+	Transform() *math.Mat4f
+	// Inverse returns the cached inverse of the transformation.
+	Inverse() *math.Mat4f
+	// SetTransform updates the transformation and its cached inverse. It panics, if the matrix is not
+	// invertible.
+	SetTransform(m math.Mat4f)
+	// PatternAt returns the color at the given point in pattern space.
+	PatternAt(point *math.Vec4f) math.Vec4f
+}
+
+// basePattern provides the transformation of all patterns and is embedded by the actual implementations.
+type basePattern struct {
+	transform math.Mat4f
+	inverse   math.Mat4f
+}
+
+func newBasePattern() basePattern {
+	return basePattern{
+		transform: math.Identity(),
+		inverse:   math.Identity(),
+	}
+}
+
+// Transform returns the pattern to object (or parent pattern) transformation.
+func (p *basePattern) Transform() *math.Mat4f {
+	return &p.transform
+}
+
+// Inverse returns the cached inverse of the transformation.
+func (p *basePattern) Inverse() *math.Mat4f {
+	return &p.inverse
+}
+
+// SetTransform updates the transformation and its cached inverse. It panics, if the matrix is not invertible.
+func (p *basePattern) SetTransform(m math.Mat4f) {
+	inverse := m
+	if !inverse.Invert() {
+		panic("tracer: the transformation of a pattern is not invertible")
+	}
+
+	p.transform = m
+	p.inverse = inverse
+}
+
+// PatternAtShape returns the color of the pattern at the given world point on the shape.
+func PatternAtShape(p Pattern, s Shape, worldPoint *math.Vec4f) math.Vec4f {
+	objectPoint := WorldToObject(s, worldPoint)
+	return nestedPatternAt(p, &objectPoint)
+}
+
+// nestedPatternAt transforms the point from the parent space into the pattern space and returns its color.
+func nestedPatternAt(p Pattern, point *math.Vec4f) math.Vec4f {
+	patternPoint := *point
+	patternPoint.Transform(p.Inverse())
+	return p.PatternAt(&patternPoint)
+}
+
+// A SolidPattern has the same color everywhere. It is the leaf of nested patterns.
+type SolidPattern struct {
+	basePattern
+	Color math.Vec4f
+}
+
+// NewSolidPattern creates a pattern of a single color.
+func NewSolidPattern(color math.Vec4f) *SolidPattern {
+	return &SolidPattern{basePattern: newBasePattern(), Color: color}
+}
+
+// PatternAt returns always the same color.
+func (p *SolidPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	return p.Color
+}
+
+// A StripePattern alternates between A and B along the x axis.
+type StripePattern struct {
+	basePattern
+	A, B Pattern
+}
+
+// NewStripePattern creates stripes, which have a width of 1 unit.
+func NewStripePattern(a, b Pattern) *StripePattern {
+	return &StripePattern{basePattern: newBasePattern(), A: a, B: b}
+}
+
+// PatternAt returns A if floor(x) is even and B otherwise.
+func (p *StripePattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	if isEven(point.X) {
+		return nestedPatternAt(p.A, point)
+	}
+
+	return nestedPatternAt(p.B, point)
+}
+
+// A GradientPattern linearly interpolates between A and B along the x axis.
+type GradientPattern struct {
+	basePattern
+	A, B Pattern
+}
+
+// NewGradientPattern creates a gradient, which repeats every unit.
+func NewGradientPattern(a, b Pattern) *GradientPattern {
+	return &GradientPattern{basePattern: newBasePattern(), A: a, B: b}
+}
+
+// PatternAt blends by the fraction of x.
+func (p *GradientPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	return p.lerp(point, point.X-math.Floor(point.X))
+}
+
+func (p *GradientPattern) lerp(point *math.Vec4f, fraction float32) math.Vec4f {
+	a := nestedPatternAt(p.A, point)
+	b := nestedPatternAt(p.B, point)
+	b.Sub(&a)
+	b.Mul(fraction)
+	a.Add(&b)
+	return a
+}
+
+// A RadialGradientPattern linearly interpolates between A and B by the distance from the y axis.
+type RadialGradientPattern struct {
+	GradientPattern
+}
+
+// NewRadialGradientPattern creates concentric gradients, which repeat every unit.
+func NewRadialGradientPattern(a, b Pattern) *RadialGradientPattern {
+	return &RadialGradientPattern{GradientPattern: *NewGradientPattern(a, b)}
+}
+
+// PatternAt blends by the fraction of the distance in the xz plane.
+func (p *RadialGradientPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	dist := math.Sqrt(point.X*point.X + point.Z*point.Z)
+	return p.lerp(point, dist-math.Floor(dist))
+}
+
+// A RingPattern alternates between A and B in concentric rings around the y axis.
+type RingPattern struct {
+	basePattern
+	A, B Pattern
+}
+
+// NewRingPattern creates rings, which have a width of 1 unit.
+func NewRingPattern(a, b Pattern) *RingPattern {
+	return &RingPattern{basePattern: newBasePattern(), A: a, B: b}
+}
+
+// PatternAt returns A if the floored distance in the xz plane is even and B otherwise.
+func (p *RingPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	if isEven(math.Sqrt(point.X*point.X + point.Z*point.Z)) {
+		return nestedPatternAt(p.A, point)
+	}
+
+	return nestedPatternAt(p.B, point)
+}
+
+// A CheckerPattern alternates between A and B in all three dimensions, like a 3D chess board.
+type CheckerPattern struct {
+	basePattern
+	A, B Pattern
+}
+
+// NewCheckerPattern creates cubes, which have a size of 1 unit.
+func NewCheckerPattern(a, b Pattern) *CheckerPattern {
+	return &CheckerPattern{basePattern: newBasePattern(), A: a, B: b}
+}
+
+// PatternAt returns A if the sum of all floored components is even and B otherwise.
+func (p *CheckerPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	if isEven(math.Floor(point.X) + math.Floor(point.Y) + math.Floor(point.Z)) {
+		return nestedPatternAt(p.A, point)
+	}
+
+	return nestedPatternAt(p.B, point)
+}
+
+// A BlendedPattern averages A and B, e.g. to create a plaid from two perpendicular stripes.
+type BlendedPattern struct {
+	basePattern
+	A, B Pattern
+}
+
+// NewBlendedPattern creates a pattern, which mixes both patterns evenly.
+func NewBlendedPattern(a, b Pattern) *BlendedPattern {
+	return &BlendedPattern{basePattern: newBasePattern(), A: a, B: b}
+}
+
+// PatternAt returns the average of A and B.
+func (p *BlendedPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	a := nestedPatternAt(p.A, point)
+	b := nestedPatternAt(p.B, point)
+	a.Add(&b)
+	a.Mul(0.5)
+	return a
+}
+
+// A PerturbedPattern jitters the point by Perlin noise, before it is passed to the nested pattern.
+type PerturbedPattern struct {
+	basePattern
+	Pattern Pattern
+	Scale   float32 // the maximum distance a point is moved, about 0.2 looks natural
+}
+
+// NewPerturbedPattern creates a pattern which distorts the given pattern.
+func NewPerturbedPattern(pattern Pattern, scale float32) *PerturbedPattern {
+	return &PerturbedPattern{basePattern: newBasePattern(), Pattern: pattern, Scale: scale}
+}
+
+// PatternAt moves each component by a different noise value and evaluates the nested pattern.
+func (p *PerturbedPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	// offset the z argument, so that the components are not jittered equally
+	jittered := *point
+	jittered.X += math.Noise(point.X, point.Y, point.Z) * p.Scale
+	jittered.Y += math.Noise(point.X, point.Y, point.Z+1) * p.Scale
+	jittered.Z += math.Noise(point.X, point.Y, point.Z+2) * p.Scale
+	return nestedPatternAt(p.Pattern, &jittered)
+}
+
+// isEven returns true if the floored value is even.
+func isEven(v float32) bool {
+	return int(math.Floor(v))%2 == 0
+}
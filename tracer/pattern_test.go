@@ -0,0 +1,154 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+var (
+	white = math.NewRGB(1, 1, 1)
+	black = math.NewRGB(0, 0, 0)
+)
+
+// testPattern is a test double, which returns the pattern space point as color.
+type testPattern struct {
+	basePattern
+}
+
+func newTestPattern() *testPattern {
+	return &testPattern{basePattern: newBasePattern()}
+}
+
+func (p *testPattern) PatternAt(point *math.Vec4f) math.Vec4f {
+	return math.NewRGB(point.X, point.Y, point.Z)
+}
+
+func TestPatternAtShape(t *testing.T) {
+	tests := []struct {
+		object  math.Mat4f
+		pattern math.Mat4f
+		point   math.Vec4f
+		res     math.Vec4f
+	}{
+		{math.Scaling(2, 2, 2), math.Identity(), math.NewPoint(2, 3, 4), math.NewRGB(1, 1.5, 2)},
+		{math.Identity(), math.Scaling(2, 2, 2), math.NewPoint(2, 3, 4), math.NewRGB(1, 1.5, 2)},
+		{math.Scaling(2, 2, 2), math.Translation(0.5, 1, 1.5), math.NewPoint(2.5, 3, 3.5), math.NewRGB(0.75, 0.5, 0.25)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s := NewSphere()
+			s.SetTransform(tt.object)
+			p := newTestPattern()
+			p.SetTransform(tt.pattern)
+			if got := PatternAtShape(p, s, &tt.point); !got.Equals(&tt.res) {
+				t.Errorf("PatternAtShape(%v) = %v, want %v", tt.point, got, tt.res)
+			}
+		})
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	w := NewSolidPattern(white)
+	b := NewSolidPattern(black)
+	tests := []struct {
+		pattern Pattern
+		point   math.Vec4f
+		res     math.Vec4f
+	}{
+		{NewSolidPattern(white), math.NewPoint(5, 7, 9), white},
+
+		// stripes are constant in y and z
+		{NewStripePattern(w, b), math.NewPoint(0, 1, 0), white},
+		{NewStripePattern(w, b), math.NewPoint(0, 0, 2), white},
+		// stripes alternate in x
+		{NewStripePattern(w, b), math.NewPoint(0.9, 0, 0), white},
+		{NewStripePattern(w, b), math.NewPoint(1, 0, 0), black},
+		{NewStripePattern(w, b), math.NewPoint(-0.1, 0, 0), black},
+		{NewStripePattern(w, b), math.NewPoint(-1, 0, 0), black},
+		{NewStripePattern(w, b), math.NewPoint(-1.1, 0, 0), white},
+
+		{NewGradientPattern(w, b), math.NewPoint(0, 0, 0), white},
+		{NewGradientPattern(w, b), math.NewPoint(0.25, 0, 0), math.NewRGB(0.75, 0.75, 0.75)},
+		{NewGradientPattern(w, b), math.NewPoint(0.5, 0, 0), math.NewRGB(0.5, 0.5, 0.5)},
+		{NewGradientPattern(w, b), math.NewPoint(0.75, 0, 0), math.NewRGB(0.25, 0.25, 0.25)},
+
+		{NewRadialGradientPattern(w, b), math.NewPoint(0, 0, 0), white},
+		{NewRadialGradientPattern(w, b), math.NewPoint(0, 0, 0.5), math.NewRGB(0.5, 0.5, 0.5)},
+		{NewRadialGradientPattern(w, b), math.NewPoint(0.6, 0, 0.8), white},
+
+		{NewRingPattern(w, b), math.NewPoint(0, 0, 0), white},
+		{NewRingPattern(w, b), math.NewPoint(1, 0, 0), black},
+		{NewRingPattern(w, b), math.NewPoint(0, 0, 1), black},
+		{NewRingPattern(w, b), math.NewPoint(0.708, 0, 0.708), black},
+
+		// checkers repeat in all dimensions
+		{NewCheckerPattern(w, b), math.NewPoint(0.99, 0, 0), white},
+		{NewCheckerPattern(w, b), math.NewPoint(1.01, 0, 0), black},
+		{NewCheckerPattern(w, b), math.NewPoint(0, 0.99, 0), white},
+		{NewCheckerPattern(w, b), math.NewPoint(0, 1.01, 0), black},
+		{NewCheckerPattern(w, b), math.NewPoint(0, 0, 0.99), white},
+		{NewCheckerPattern(w, b), math.NewPoint(0, 0, 1.01), black},
+
+		{NewBlendedPattern(w, b), math.NewPoint(0, 0, 0), math.NewRGB(0.5, 0.5, 0.5)},
+		{NewBlendedPattern(NewStripePattern(w, b), w), math.NewPoint(1.5, 0, 0), math.NewRGB(0.5, 0.5, 0.5)},
+
+		// noise is 0 at integer lattice points
+		{NewPerturbedPattern(NewStripePattern(w, b), 0.5), math.NewPoint(1, 0, 0), black},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := tt.pattern.PatternAt(&tt.point); !got.Equals(&tt.res) {
+				t.Errorf("PatternAt(%v) = %v, want %v", tt.point, got, tt.res)
+			}
+		})
+	}
+}
+
+func TestPatterns_Nested(t *testing.T) {
+	// the nested stripes are rotated, so they alternate along z instead of x
+	inner := NewStripePattern(NewSolidPattern(white), NewSolidPattern(black))
+	inner.SetTransform(math.RotationY(math.Pi / 2))
+	outer := NewStripePattern(inner, NewSolidPattern(black))
+
+	tests := []struct {
+		point math.Vec4f
+		res   math.Vec4f
+	}{
+		{math.NewPoint(0.5, 0, -0.5), white},
+		{math.NewPoint(0.5, 0, -1.5), black},
+		{math.NewPoint(0.5, 0, 0.5), black},
+		{math.NewPoint(1.5, 0, -0.5), black},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := outer.PatternAt(&tt.point); !got.Equals(&tt.res) {
+				t.Errorf("PatternAt(%v) = %v, want %v", tt.point, got, tt.res)
+			}
+		})
+	}
+
+	// the perturbed pattern must not look like the regular one
+	perturbed := NewPerturbedPattern(NewGradientPattern(NewSolidPattern(white), NewSolidPattern(black)), 0.3)
+	regular := NewGradientPattern(NewSolidPattern(white), NewSolidPattern(black))
+	p := math.NewPoint(0.3, 0.6, 0.7)
+	a := perturbed.PatternAt(&p)
+	b := regular.PatternAt(&p)
+	if a.Equals(&b) {
+		t.Errorf("PatternAt(%v) = %v, want a jittered color", p, a)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Plane is an infinite xz plane through the object origin.
+type Plane struct {
+	baseShape
+}
+
+// NewPlane allocates a plane with the identity transformation.
+func NewPlane() *Plane {
+	return &Plane{baseShape: newBaseShape()}
+}
+
+// LocalIntersect appends at most one intersection. A ray which is parallel to the plane never hits
+// it, even if it is coplanar, because the plane is infinitely thin.
+func (p *Plane) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	if math.Abs(ray.Direction.Y) < math.Epsilon {
+		return xs
+	}
+
+	t := -ray.Origin.Y / ray.Direction.Y
+	return append(xs, Intersection{T: t, Object: p})
+}
+
+// LocalNormalAt always points upwards.
+func (p *Plane) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	return math.NewVector(0, 1, 0)
+}
+
+// Bounds returns a box, which is infinite in x and z but flat in y.
+func (p *Plane) Bounds() math.BoundingBox {
+	return math.NewBoundingBox(math.NewPoint(math.Inf(-1), 0, math.Inf(-1)), math.NewPoint(math.Inf(1), 0, math.Inf(1)))
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestPlane_LocalNormalAt(t *testing.T) {
+	p := NewPlane()
+	want := math.NewVector(0, 1, 0)
+	for _, point := range []math.Vec4f{math.NewPoint(0, 0, 0), math.NewPoint(10, 0, -10), math.NewPoint(-5, 0, 150)} {
+		if n := p.LocalNormalAt(&point, nil); !n.Equals(&want) {
+			t.Errorf("LocalNormalAt(%v) = %v, want %v", point, n, want)
+		}
+	}
+}
+
+func TestPlane_LocalIntersect(t *testing.T) {
+	tests := []struct {
+		ray math.Ray
+		res []float32
+	}{
+		// parallel
+		{math.NewRay(math.NewPoint(0, 10, 0), math.NewVector(0, 0, 1)), nil},
+		// coplanar
+		{math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 0, 1)), nil},
+		// from above
+		{math.NewRay(math.NewPoint(0, 1, 0), math.NewVector(0, -1, 0)), []float32{1}},
+		// from below
+		{math.NewRay(math.NewPoint(0, -1, 0), math.NewVector(0, 1, 0)), []float32{1}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			p := NewPlane()
+			xs := p.LocalIntersect(&tt.ray, nil)
+			if len(xs) != len(tt.res) {
+				t.Fatalf("len(xs) = %v, want %v", len(xs), len(tt.res))
+			}
+
+			for i, x := range xs {
+				if !math.Equalf(x.T, tt.res[i]) {
+					t.Errorf("xs[%d].T = %v, want %v", i, x.T, tt.res[i])
+				}
+
+				if x.Object != p {
+					t.Errorf("xs[%d].Object = %v, want %v", i, x.Object, p)
+				}
+			}
+		})
+	}
+}
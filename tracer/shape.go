@@ -0,0 +1,169 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Shape is a primitive or a composition of primitives, which can be intersected by rays. Each shape
+// is defined in its own object space and the world to object transformation is done by Intersect and
+// NormalAt, so that implementations only need to care about the local space.
+type Shape interface {
+	// Transform returns the object to parent transformation.
+	Transform() *math.Mat4f
+	// Inverse returns the cached inverse of the transformation.
+	Inverse() *math.Mat4f
+	// SetTransform updates the transformation and its cached inverse. It panics, if the matrix is not
+	// invertible, e.g. because it scales an axis by 0.
+	SetTransform(m math.Mat4f)
+	// Material returns the surface material, which can be modified in place.
+	Material() *Material
+	// SetMaterial replaces the surface material.
+	SetMaterial(m Material)
+	// Parent returns the shape which contains this shape or nil.
+	Parent() Shape
+	// SetParent updates the parent, which is usually only done by the container itself.
+	SetParent(p Shape)
+	// LocalIntersect appends the intersections with the ray, which is already in object space, to xs.
+	LocalIntersect(r *math.Ray, xs Intersections) Intersections
+	// LocalNormalAt returns the (not necessarily normalized) normal at the given point in object space.
+	// The hit is the intersection, which belongs to the point. It is only required by shapes which
+	// interpolate their normals, like SmoothTriangle, and may be nil otherwise.
+	LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f
+	// Bounds returns the box in object space, which encloses the shape.
+	Bounds() math.BoundingBox
+}
+
+// baseShape provides the common state of all shapes and is embedded by the actual implementations.
+type baseShape struct {
+	transform math.Mat4f
+	inverse   math.Mat4f // cached, because each intersection requires the inverse
+	material  Material
+	parent    Shape
+}
+
+func newBaseShape() baseShape {
+	return baseShape{
+		transform: math.Identity(),
+		inverse:   math.Identity(),
+		material:  NewMaterial(),
+	}
+}
+
+// Transform returns the object to parent transformation.
+func (s *baseShape) Transform() *math.Mat4f {
+	return &s.transform
+}
+
+// Inverse returns the cached inverse of the transformation.
+func (s *baseShape) Inverse() *math.Mat4f {
+	return &s.inverse
+}
+
+// SetTransform updates the transformation and its cached inverse. It panics, if the matrix is not invertible.
+func (s *baseShape) SetTransform(m math.Mat4f) {
+	inverse := m
+	if !inverse.Invert() {
+		panic("tracer: the transformation of a shape is not invertible")
+	}
+
+	s.transform = m
+	s.inverse = inverse
+	boundsChanged(s.parent)
+}
+
+// Material returns the surface material, which can be modified in place.
+func (s *baseShape) Material() *Material {
+	return &s.material
+}
+
+// SetMaterial replaces the surface material.
+func (s *baseShape) SetMaterial(m Material) {
+	s.material = m
+}
+
+// Parent returns the shape which contains this shape or nil.
+func (s *baseShape) Parent() Shape {
+	return s.parent
+}
+
+// SetParent updates the parent.
+func (s *baseShape) SetParent(p Shape) {
+	s.parent = p
+}
+
+// boundsUpdater is implemented by containers, which cache the bounds of their children.
+type boundsUpdater interface {
+	updateBounds()
+}
+
+// boundsChanged notifies the parent, that the bounds of one of its children have changed. This happens
+// eagerly, so that intersecting never writes and is safe for concurrent use.
+func boundsChanged(parent Shape) {
+	if u, ok := parent.(boundsUpdater); ok {
+		u.updateBounds()
+	}
+}
+
+// ParentSpaceBounds returns the bounds of the shape transformed into the space of its parent.
+func ParentSpaceBounds(s Shape) math.BoundingBox {
+	b := s.Bounds()
+	b.Transform(s.Transform())
+	return b
+}
+
+// Intersect transforms the ray into the object space of the shape and appends the intersections to xs.
+func Intersect(s Shape, r *math.Ray, xs Intersections) Intersections {
+	ray := *r
+	ray.Transform(s.Inverse())
+	return s.LocalIntersect(&ray, xs)
+}
+
+// NormalAt returns the normalized surface normal at the given world point, which is assumed to be on the shape.
+// The transformations of all parents are respected. See Shape.LocalNormalAt for the hit.
+func NormalAt(s Shape, worldPoint *math.Vec4f, hit *Intersection) math.Vec4f {
+	objectPoint := WorldToObject(s, worldPoint)
+	objectNormal := s.LocalNormalAt(&objectPoint, hit)
+	return NormalToWorld(s, &objectNormal)
+}
+
+// WorldToObject converts the world point into the object space of the shape, by applying the inverse
+// transformations from the outermost parent down to the shape.
+func WorldToObject(s Shape, point *math.Vec4f) math.Vec4f {
+	p := *point
+	if parent := s.Parent(); parent != nil {
+		p = WorldToObject(parent, &p)
+	}
+
+	p.Transform(s.Inverse())
+	return p
+}
+
+// NormalToWorld converts the normal from the object space of the shape into world space, by applying
+// the transposed inverse transformations from the shape up to the outermost parent. The result is normalized.
+func NormalToWorld(s Shape, normal *math.Vec4f) math.Vec4f {
+	// normals must be transformed by the transposed inverse to stay perpendicular to the surface
+	normalMatrix := *s.Inverse()
+	normalMatrix.Transpose()
+	n := *normal
+	n.Transform(&normalMatrix)
+	n.W = 0 // the translation part of the transposed matrix spoils w
+	n.Normalize()
+
+	if parent := s.Parent(); parent != nil {
+		n = NormalToWorld(parent, &n)
+	}
+
+	return n
+}
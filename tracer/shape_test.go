@@ -0,0 +1,194 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+// testShape is a test double, which records the local ray and returns the local point as its normal.
+type testShape struct {
+	baseShape
+	savedRay math.Ray
+}
+
+func newTestShape() *testShape {
+	return &testShape{baseShape: newBaseShape()}
+}
+
+func (s *testShape) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	s.savedRay = *ray
+	return xs
+}
+
+func (s *testShape) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	return math.NewVector(point.X, point.Y, point.Z)
+}
+
+func (s *testShape) Bounds() math.BoundingBox {
+	return math.NewBoundingBox(math.NewPoint(-1, -1, -1), math.NewPoint(1, 1, 1))
+}
+
+func TestShape_Defaults(t *testing.T) {
+	s := newTestShape()
+	id := math.Identity()
+	if !s.Transform().Equals(&id) {
+		t.Errorf("Transform() = %v, want %v", *s.Transform(), id)
+	}
+
+	if m := NewMaterial(); *s.Material() != m {
+		t.Errorf("Material() = %v, want %v", *s.Material(), m)
+	}
+
+	if s.Parent() != nil {
+		t.Errorf("Parent() = %v, want nil", s.Parent())
+	}
+
+	tr := math.Translation(2, 3, 4)
+	s.SetTransform(tr)
+	if !s.Transform().Equals(&tr) {
+		t.Errorf("Transform() = %v, want %v", *s.Transform(), tr)
+	}
+}
+
+func TestSetTransform_NotInvertible(t *testing.T) {
+	sphere := NewSphere()
+	pattern := newTestPattern()
+	camera := NewCamera(10, 10, math.Pi/2)
+	tests := []struct {
+		setTransform func(m math.Mat4f)
+		transform    *math.Mat4f
+	}{
+		{sphere.SetTransform, sphere.Transform()},
+		{pattern.SetTransform, pattern.Transform()},
+		{camera.SetTransform, camera.Transform()},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SetTransform() did not panic")
+				}
+
+				// the previous transformation is kept
+				if want := math.Identity(); !tt.transform.Equals(&want) {
+					t.Errorf("Transform() = %v, want %v", tt.transform, want)
+				}
+			}()
+
+			tt.setTransform(math.Scaling(1, 0, 1))
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		transform         math.Mat4f
+		origin, direction math.Vec4f
+	}{
+		{math.Scaling(2, 2, 2), math.NewPoint(0, 0, -2.5), math.NewVector(0, 0, 0.5)},
+		{math.Translation(5, 0, 0), math.NewPoint(-5, 0, -5), math.NewVector(0, 0, 1)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+			s := newTestShape()
+			s.SetTransform(tt.transform)
+			Intersect(s, &r, nil)
+			if !s.savedRay.Origin.Equals(&tt.origin) {
+				t.Errorf("Origin = %v, want %v", s.savedRay.Origin, tt.origin)
+			}
+
+			if !s.savedRay.Direction.Equals(&tt.direction) {
+				t.Errorf("Direction = %v, want %v", s.savedRay.Direction, tt.direction)
+			}
+		})
+	}
+}
+
+func TestNormalAt(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		transform math.Mat4f
+		point     math.Vec4f
+		res       math.Vec4f
+	}{
+		{math.Translation(0, 1, 0), math.NewPoint(0, 1.70711, -0.70711), math.NewVector(0, 0.70711, -0.70711)},
+		{math.Identity().RotateZ(math.Pi/5).Scale(1, 0.5, 1), math.NewPoint(0, sqrt2, -sqrt2), math.NewVector(0, 0.97014, -0.24254)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s := newTestShape()
+			s.SetTransform(tt.transform)
+			n := NormalAt(s, &tt.point, nil)
+			if !equalsApprox(&n, &tt.res) {
+				t.Errorf("NormalAt(%v) = %v, want %v", tt.point, n, tt.res)
+			}
+		})
+	}
+}
+
+// equalsBounds compares exactly, because bounds may be infinite.
+func equalsBounds(a, b math.BoundingBox) bool {
+	return a.Min.X == b.Min.X && a.Min.Y == b.Min.Y && a.Min.Z == b.Min.Z &&
+		a.Max.X == b.Max.X && a.Max.Y == b.Max.Y && a.Max.Z == b.Max.Z
+}
+
+func TestShape_Bounds(t *testing.T) {
+	inf := math.Inf(1)
+	cylinder := NewCylinder()
+	cylinder.Minimum = -5
+	cylinder.Maximum = 3
+	cone := NewCone()
+	cone.Minimum = -5
+	cone.Maximum = 3
+
+	tests := []struct {
+		shape    Shape
+		min, max math.Vec4f
+	}{
+		{NewSphere(), math.NewPoint(-1, -1, -1), math.NewPoint(1, 1, 1)},
+		{NewPlane(), math.NewPoint(-inf, 0, -inf), math.NewPoint(inf, 0, inf)},
+		{NewCube(), math.NewPoint(-1, -1, -1), math.NewPoint(1, 1, 1)},
+		{NewCylinder(), math.NewPoint(-1, -inf, -1), math.NewPoint(1, inf, 1)},
+		{cylinder, math.NewPoint(-1, -5, -1), math.NewPoint(1, 3, 1)},
+		{NewCone(), math.NewPoint(-inf, -inf, -inf), math.NewPoint(inf, inf, inf)},
+		{cone, math.NewPoint(-5, -5, -5), math.NewPoint(5, 3, 5)},
+		{
+			NewTriangle(math.NewPoint(-3, 7, 2), math.NewPoint(6, 2, -4), math.NewPoint(2, -1, -1)),
+			math.NewPoint(-3, -1, -4), math.NewPoint(6, 7, 2),
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			want := math.NewBoundingBox(tt.min, tt.max)
+			if got := tt.shape.Bounds(); !equalsBounds(got, want) {
+				t.Errorf("Bounds() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParentSpaceBounds(t *testing.T) {
+	s := NewSphere()
+	s.SetTransform(math.Scaling(0.5, 2, 4).Translate(1, -3, 5))
+
+	want := math.NewBoundingBox(math.NewPoint(0.5, -5, 1), math.NewPoint(1.5, -1, 9))
+	if got := ParentSpaceBounds(s); !equalsBounds(got, want) {
+		t.Errorf("ParentSpaceBounds() = %v, want %v", got, want)
+	}
+}
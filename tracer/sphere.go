@@ -0,0 +1,62 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// A Sphere is a unit sphere at the object origin, which is moved, rotated and scaled by its transformation.
+type Sphere struct {
+	baseShape
+}
+
+// NewSphere allocates a unit sphere with the identity transformation.
+func NewSphere() *Sphere {
+	return &Sphere{baseShape: newBaseShape()}
+}
+
+// LocalIntersect appends the intersections of the ray with the sphere to xs and returns the result. A ray
+// either misses the sphere or hits it at two points, which may be equal if the ray is a tangent.
+func (s *Sphere) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	center := math.NewPoint(0, 0, 0)
+	sphereToRay := ray.Origin
+	sphereToRay.Sub(&center)
+
+	a := ray.Direction.Dot(&ray.Direction)
+	b := 2 * ray.Direction.Dot(&sphereToRay)
+	c := sphereToRay.Dot(&sphereToRay) - 1
+
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return xs
+	}
+
+	sqrtDisc := math.Sqrt(discriminant)
+	t1 := (-b - sqrtDisc) / (2 * a)
+	t2 := (-b + sqrtDisc) / (2 * a)
+
+	return append(xs, Intersection{T: t1, Object: s}, Intersection{T: t2, Object: s})
+}
+
+// LocalNormalAt returns the vector from the center to the point, which is the normal of a unit sphere.
+func (s *Sphere) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	n := *point
+	n.W = 0
+	return n
+}
+
+// Bounds returns the unit cube.
+func (s *Sphere) Bounds() math.BoundingBox {
+	return math.NewBoundingBox(math.NewPoint(-1, -1, -1), math.NewPoint(1, 1, 1))
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestSphere_Intersect(t *testing.T) {
+	tests := []struct {
+		ray       math.Ray
+		transform math.Mat4f
+		res       []float32
+	}{
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)), math.Identity(), []float32{4, 6}},
+		{math.NewRay(math.NewPoint(0, 1, -5), math.NewVector(0, 0, 1)), math.Identity(), []float32{5, 5}},
+		{math.NewRay(math.NewPoint(0, 2, -5), math.NewVector(0, 0, 1)), math.Identity(), nil},
+		{math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 0, 1)), math.Identity(), []float32{-1, 1}},
+		{math.NewRay(math.NewPoint(0, 0, 5), math.NewVector(0, 0, 1)), math.Identity(), []float32{-6, -4}},
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)), math.Scaling(2, 2, 2), []float32{3, 7}},
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)), math.Translation(5, 0, 0), nil},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s := NewSphere()
+			s.SetTransform(tt.transform)
+			xs := Intersect(s, &tt.ray, nil)
+			if len(xs) != len(tt.res) {
+				t.Fatalf("len(xs) = %v, want %v", len(xs), len(tt.res))
+			}
+
+			for i, x := range xs {
+				if !math.Equalf(x.T, tt.res[i]) {
+					t.Errorf("xs[%d].T = %v, want %v", i, x.T, tt.res[i])
+				}
+
+				if x.Object != s {
+					t.Errorf("xs[%d].Object = %v, want %v", i, x.Object, s)
+				}
+			}
+		})
+	}
+}
+
+func TestSphere_NormalAt(t *testing.T) {
+	sqrt3 := math.Sqrt(3) / 3
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		transform math.Mat4f
+		point     math.Vec4f
+		res       math.Vec4f
+	}{
+		{math.Identity(), math.NewPoint(1, 0, 0), math.NewVector(1, 0, 0)},
+		{math.Identity(), math.NewPoint(0, 1, 0), math.NewVector(0, 1, 0)},
+		{math.Identity(), math.NewPoint(0, 0, 1), math.NewVector(0, 0, 1)},
+		{math.Identity(), math.NewPoint(sqrt3, sqrt3, sqrt3), math.NewVector(sqrt3, sqrt3, sqrt3)},
+		{math.Translation(0, 1, 0), math.NewPoint(0, 1.70711, -0.70711), math.NewVector(0, 0.70711, -0.70711)},
+		{math.Identity().RotateZ(math.Pi/5).Scale(1, 0.5, 1), math.NewPoint(0, sqrt2, -sqrt2), math.NewVector(0, 0.97014, -0.24254)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s := NewSphere()
+			s.SetTransform(tt.transform)
+			n := NormalAt(s, &tt.point, nil)
+			if !equalsApprox(&n, &tt.res) {
+				t.Errorf("NormalAt(%v) = %v, want %v", tt.point, n, tt.res)
+			}
+
+			l := n.Len()
+			if !math.Equalf(l, 1) {
+				t.Errorf("len(NormalAt(%v)) = %v, want 1", tt.point, l)
+			}
+		})
+	}
+}
+
+func TestSphere_Material(t *testing.T) {
+	s := NewSphere()
+	want := NewMaterial()
+	if *s.Material() != want {
+		t.Errorf("Material() = %v, want %v", *s.Material(), want)
+	}
+
+	m := NewMaterial()
+	m.Ambient = 1
+	s.SetMaterial(m)
+	if *s.Material() != m {
+		t.Errorf("Material() = %v, want %v", *s.Material(), m)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import "github.com/torbenschinke/rtc/math"
+
+// minDeterminant is the threshold below which a ray is treated as parallel to a triangle. It is much smaller
+// than math.Epsilon, because the determinant scales with the triangle area and the triangles of large
+// meshes are tiny.
+const minDeterminant = math.Epsilon * math.Epsilon
+
+// A Triangle is a flat triangle, defined by three points. The edges and the normal are precomputed,
+// so the fields must not be modified after construction.
+type Triangle struct {
+	baseShape
+	P1, P2, P3 math.Vec4f
+	E1, E2     math.Vec4f // the edges from P1 to P2 and from P1 to P3
+	Normal     math.Vec4f
+}
+
+// NewTriangle allocates a triangle and precomputes its edges and normal.
+func NewTriangle(p1, p2, p3 math.Vec4f) *Triangle {
+	t := &Triangle{baseShape: newBaseShape(), P1: p1, P2: p2, P3: p3}
+	t.E1 = p2
+	t.E1.Sub(&p1)
+	t.E2 = p3
+	t.E2.Sub(&p1)
+
+	t.Normal = t.E2
+	t.Normal.Cross(&t.E1)
+	t.Normal.Normalize()
+
+	return t
+}
+
+// LocalIntersect appends at most one intersection, including the barycentric coordinates of the hit.
+func (t *Triangle) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	return intersectTriangle(t, t, ray, xs)
+}
+
+// LocalNormalAt returns the same normal everywhere.
+func (t *Triangle) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	return t.Normal
+}
+
+// Bounds returns the box around the three points.
+func (t *Triangle) Bounds() math.BoundingBox {
+	b := math.EmptyBoundingBox()
+	b.AddPoint(&t.P1)
+	b.AddPoint(&t.P2)
+	b.AddPoint(&t.P3)
+	return b
+}
+
+// intersectTriangle implements the Möller–Trumbore algorithm and appends the intersection for the shape s.
+func intersectTriangle(s Shape, tri *Triangle, ray *math.Ray, xs Intersections) Intersections {
+	dirCrossE2 := ray.Direction
+	dirCrossE2.Cross(&tri.E2)
+	det := tri.E1.Dot(&dirCrossE2)
+	if math.Abs(det) < minDeterminant {
+		return xs
+	}
+
+	f := 1 / det
+	p1ToOrigin := ray.Origin
+	p1ToOrigin.Sub(&tri.P1)
+	u := f * p1ToOrigin.Dot(&dirCrossE2)
+	if u < 0 || u > 1 {
+		return xs
+	}
+
+	originCrossE1 := p1ToOrigin
+	originCrossE1.Cross(&tri.E1)
+	v := f * ray.Direction.Dot(&originCrossE1)
+	if v < 0 || u+v > 1 {
+		return xs
+	}
+
+	t := f * tri.E2.Dot(&originCrossE1)
+	return append(xs, Intersection{T: t, Object: s, U: u, V: v})
+}
+
+// A SmoothTriangle is a Triangle with a normal at each point, which are interpolated across the surface
+// to fake a curved surface.
+type SmoothTriangle struct {
+	Triangle
+	N1, N2, N3 math.Vec4f
+}
+
+// NewSmoothTriangle allocates a triangle with the given points and their normals.
+func NewSmoothTriangle(p1, p2, p3, n1, n2, n3 math.Vec4f) *SmoothTriangle {
+	return &SmoothTriangle{Triangle: *NewTriangle(p1, p2, p3), N1: n1, N2: n2, N3: n3}
+}
+
+// LocalIntersect appends at most one intersection, including the barycentric coordinates of the hit.
+func (t *SmoothTriangle) LocalIntersect(ray *math.Ray, xs Intersections) Intersections {
+	return intersectTriangle(t, &t.Triangle, ray, xs)
+}
+
+// LocalNormalAt interpolates the normals by the barycentric coordinates of the hit, which is required.
+func (t *SmoothTriangle) LocalNormalAt(point *math.Vec4f, hit *Intersection) math.Vec4f {
+	n2 := t.N2
+	n2.Mul(hit.U)
+	n3 := t.N3
+	n3.Mul(hit.V)
+	n1 := t.N1
+	n1.Mul(1 - hit.U - hit.V)
+
+	n1.Add(&n2)
+	n1.Add(&n3)
+	return n1
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+func TestNewTriangle(t *testing.T) {
+	p1 := math.NewPoint(0, 1, 0)
+	p2 := math.NewPoint(-1, 0, 0)
+	p3 := math.NewPoint(1, 0, 0)
+	tri := NewTriangle(p1, p2, p3)
+
+	tests := []struct {
+		got, want math.Vec4f
+	}{
+		{tri.P1, p1},
+		{tri.P2, p2},
+		{tri.P3, p3},
+		{tri.E1, math.NewVector(-1, -1, 0)},
+		{tri.E2, math.NewVector(1, -1, 0)},
+		{tri.Normal, math.NewVector(0, 0, -1)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if !tt.got.Equals(&tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	for _, p := range []math.Vec4f{math.NewPoint(0, 0.5, 0), math.NewPoint(-0.5, 0.75, 0), math.NewPoint(0.5, 0.25, 0)} {
+		if n := tri.LocalNormalAt(&p, nil); !n.Equals(&tri.Normal) {
+			t.Errorf("LocalNormalAt(%v) = %v, want %v", p, n, tri.Normal)
+		}
+	}
+}
+
+func TestTriangle_LocalIntersect(t *testing.T) {
+	tests := []struct {
+		ray math.Ray
+		res []float32
+	}{
+		// parallel
+		{math.NewRay(math.NewPoint(0, -1, -2), math.NewVector(0, 1, 0)), nil},
+		// misses the edges
+		{math.NewRay(math.NewPoint(1, 1, -2), math.NewVector(0, 0, 1)), nil},
+		{math.NewRay(math.NewPoint(-1, 1, -2), math.NewVector(0, 0, 1)), nil},
+		{math.NewRay(math.NewPoint(0, -1, -2), math.NewVector(0, 0, 1)), nil},
+		// hit
+		{math.NewRay(math.NewPoint(0, 0.5, -2), math.NewVector(0, 0, 1)), []float32{2}},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tri := NewTriangle(math.NewPoint(0, 1, 0), math.NewPoint(-1, 0, 0), math.NewPoint(1, 0, 0))
+			xs := tri.LocalIntersect(&tt.ray, nil)
+			assertTs(t, xs, tt.res)
+		})
+	}
+}
+
+func newTestSmoothTriangle() *SmoothTriangle {
+	return NewSmoothTriangle(
+		math.NewPoint(0, 1, 0), math.NewPoint(-1, 0, 0), math.NewPoint(1, 0, 0),
+		math.NewVector(0, 1, 0), math.NewVector(-1, 0, 0), math.NewVector(1, 0, 0),
+	)
+}
+
+func TestSmoothTriangle_LocalIntersect(t *testing.T) {
+	tri := newTestSmoothTriangle()
+	r := math.NewRay(math.NewPoint(-0.2, 0.3, -2), math.NewVector(0, 0, 1))
+	xs := tri.LocalIntersect(&r, nil)
+	if len(xs) != 1 {
+		t.Fatalf("len(xs) = %v, want 1", len(xs))
+	}
+
+	if xs[0].Object != tri {
+		t.Errorf("Object = %v, want %v", xs[0].Object, tri)
+	}
+
+	if !math.Equalf(xs[0].U, 0.45) || !math.Equalf(xs[0].V, 0.25) {
+		t.Errorf("U, V = %v, %v, want 0.45, 0.25", xs[0].U, xs[0].V)
+	}
+}
+
+func TestSmoothTriangle_NormalAt(t *testing.T) {
+	tri := newTestSmoothTriangle()
+	hit := Intersection{T: 1, Object: tri, U: 0.45, V: 0.25}
+	p := math.NewPoint(0, 0, 0)
+	want := math.NewVector(-0.5547, 0.83205, 0)
+
+	n := NormalAt(tri, &p, &hit)
+	if !equalsApprox(&n, &want) {
+		t.Errorf("NormalAt() = %v, want %v", n, want)
+	}
+
+	// the normal is prepared from the hit
+	r := math.NewRay(math.NewPoint(-0.2, 0.3, -2), math.NewVector(0, 0, 1))
+	xs := Intersections{hit}
+	comps := PrepareComputations(&xs[0], &r, xs)
+	if !equalsApprox(&comps.NormalV, &want) {
+		t.Errorf("NormalV = %v, want %v", comps.NormalV, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"testing"
+)
+
+// approxEpsilon is used to compare against the expected values from the book, which are
+// rounded to 5 decimal places and therefore fail the stricter math.Equalf check.
+const approxEpsilon = 0.0001
+
+// equalsApprox compares two tuples using approxEpsilon.
+func equalsApprox(a, b *math.Vec4f) bool {
+	return math.Abs(a.X-b.X) < approxEpsilon &&
+		math.Abs(a.Y-b.Y) < approxEpsilon &&
+		math.Abs(a.Z-b.Z) < approxEpsilon &&
+		math.Abs(a.W-b.W) < approxEpsilon
+}
+
+// colorEpsilon is used for colors, which are the result of multiple bounced rays. The book
+// expects them with float64 precision and a smaller bias for the over and under points.
+const colorEpsilon = 0.001
+
+// equalsColor compares two colors using colorEpsilon.
+func equalsColor(a, b *math.Vec4f) bool {
+	return math.Abs(a.X-b.X) < colorEpsilon &&
+		math.Abs(a.Y-b.Y) < colorEpsilon &&
+		math.Abs(a.Z-b.Z) < colorEpsilon &&
+		math.Abs(a.W-b.W) < colorEpsilon
+}
+
+// assertTs checks that the distances of the intersections are approximately the expected ones.
+func assertTs(t *testing.T, xs Intersections, want []float32) {
+	t.Helper()
+	if len(xs) != len(want) {
+		t.Fatalf("len(xs) = %v, want %v", len(xs), len(want))
+	}
+
+	for i := range want {
+		if math.Abs(xs[i].T-want[i]) > approxEpsilon {
+			t.Errorf("xs[%d].T = %v, want %v", i, xs[i].T, want[i])
+		}
+	}
+}
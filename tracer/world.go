@@ -0,0 +1,153 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"sort"
+)
+
+// DefaultMaxDepth is the default recursion limit for reflected and refracted rays.
+const DefaultMaxDepth = 5
+
+// A World is the scene, which contains all objects and light sources.
+type World struct {
+	Objects  []Shape
+	Lights   []PointLight
+	MaxDepth int // how often rays are reflected or refracted, at most
+}
+
+// NewWorld allocates an empty world without objects and without lights.
+func NewWorld() *World {
+	return &World{MaxDepth: DefaultMaxDepth}
+}
+
+// Intersect appends the intersections of the ray with all objects to xs. The result is sorted by t.
+func (w *World) Intersect(r *math.Ray, xs Intersections) Intersections {
+	for _, obj := range w.Objects {
+		xs = Intersect(obj, r, xs)
+	}
+
+	sort.Sort(xs)
+	return xs
+}
+
+// ShadeHit returns the color at the prepared intersection, summed up for all light sources. Light sources
+// which are occluded by other objects only contribute their ambient part. The remaining value limits
+// the recursion depth for reflected and refracted rays.
+func (w *World) ShadeHit(comps *Computations, remaining int) math.Vec4f {
+	res := math.NewRGB(0, 0, 0)
+	for i := range w.Lights {
+		light := &w.Lights[i]
+		inShadow := w.IsShadowed(light, &comps.OverPoint)
+		c := Lighting(comps.Object.Material(), comps.Object, light, &comps.OverPoint, &comps.EyeV, &comps.NormalV, inShadow)
+		res.Add(&c)
+	}
+
+	reflected := w.ReflectedColor(comps, remaining)
+	refracted := w.RefractedColor(comps, remaining)
+
+	m := comps.Object.Material()
+	if m.Reflective > 0 && m.Transparency > 0 {
+		reflectance := Schlick(comps)
+		reflected.Mul(reflectance)
+		refracted.Mul(1 - reflectance)
+	}
+
+	res.Add(&reflected)
+	res.Add(&refracted)
+	res.W = 1
+	return res
+}
+
+// ColorAt intersects the world with the ray and returns the shaded color of the hit, or black if nothing
+// was hit. Reflected and refracted rays are followed up to MaxDepth times.
+func (w *World) ColorAt(r *math.Ray) math.Vec4f {
+	return w.colorAt(r, w.MaxDepth)
+}
+
+func (w *World) colorAt(r *math.Ray, remaining int) math.Vec4f {
+	xs := w.Intersect(r, nil)
+	hit := xs.Hit()
+	if hit == nil {
+		return math.NewRGB(0, 0, 0)
+	}
+
+	comps := PrepareComputations(hit, r, xs)
+	return w.ShadeHit(&comps, remaining)
+}
+
+// ReflectedColor returns the color seen in the reflection direction, scaled by the reflectivity of the
+// material. If the material is not reflective or the recursion limit has been reached, black is returned.
+func (w *World) ReflectedColor(comps *Computations, remaining int) math.Vec4f {
+	reflective := comps.Object.Material().Reflective
+	if remaining < 1 || reflective == 0 {
+		return math.NewRGB(0, 0, 0)
+	}
+
+	r := math.NewRay(comps.OverPoint, comps.ReflectV)
+	color := w.colorAt(&r, remaining-1)
+	color.Mul(reflective)
+	color.W = 1
+	return color
+}
+
+// RefractedColor returns the color seen through the surface, scaled by the transparency of the material.
+// If the material is opaque, the recursion limit has been reached or the light is totally reflected
+// internally, black is returned.
+func (w *World) RefractedColor(comps *Computations, remaining int) math.Vec4f {
+	transparency := comps.Object.Material().Transparency
+	if remaining < 1 || transparency == 0 {
+		return math.NewRGB(0, 0, 0)
+	}
+
+	// Snell's law
+	nRatio := comps.N1 / comps.N2
+	cosI := comps.EyeV.Dot(&comps.NormalV)
+	sin2t := nRatio * nRatio * (1 - cosI*cosI)
+	if sin2t > 1 {
+		// total internal reflection
+		return math.NewRGB(0, 0, 0)
+	}
+
+	cosT := math.Sqrt(1 - sin2t)
+
+	// direction = normalv * (n_ratio * cos_i - cos_t) - eyev * n_ratio
+	direction := comps.NormalV
+	direction.Mul(nRatio*cosI - cosT)
+	eyev := comps.EyeV
+	eyev.Mul(nRatio)
+	direction.Sub(&eyev)
+
+	r := math.NewRay(comps.UnderPoint, direction)
+	color := w.colorAt(&r, remaining-1)
+	color.Mul(transparency)
+	color.W = 1
+	return color
+}
+
+// IsShadowed returns true, if any object is between the point and the light source.
+func (w *World) IsShadowed(light *PointLight, point *math.Vec4f) bool {
+	v := light.Position
+	v.Sub(point)
+	distance := v.Len()
+	v.Normalize()
+
+	r := math.NewRay(*point, v)
+	xs := w.Intersect(&r, nil)
+	hit := xs.Hit()
+
+	return hit != nil && hit.T < distance
+}
@@ -0,0 +1,372 @@
+// Copyright 2021 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"github.com/torbenschinke/rtc/math"
+	"strconv"
+	"testing"
+)
+
+// defaultWorld returns the world with two concentric spheres and a single light, as used by the book.
+func defaultWorld() *World {
+	s1 := NewSphere()
+	s1.Material().Color = math.NewRGB(0.8, 1.0, 0.6)
+	s1.Material().Diffuse = 0.7
+	s1.Material().Specular = 0.2
+
+	s2 := NewSphere()
+	s2.SetTransform(math.Scaling(0.5, 0.5, 0.5))
+
+	w := NewWorld()
+	w.Objects = append(w.Objects, s1, s2)
+	w.Lights = append(w.Lights, NewPointLight(math.NewPoint(-10, 10, -10), math.NewRGB(1, 1, 1)))
+
+	return w
+}
+
+func TestWorld_Intersect(t *testing.T) {
+	w := defaultWorld()
+	r := math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1))
+	xs := w.Intersect(&r, nil)
+	want := []float32{4, 4.5, 5.5, 6}
+	if len(xs) != len(want) {
+		t.Fatalf("len(xs) = %v, want %v", len(xs), len(want))
+	}
+
+	for i := range want {
+		if !math.Equalf(xs[i].T, want[i]) {
+			t.Errorf("xs[%d].T = %v, want %v", i, xs[i].T, want[i])
+		}
+	}
+}
+
+func TestWorld_ShadeHit(t *testing.T) {
+	tests := []struct {
+		light PointLight
+		ray   math.Ray
+		obj   int
+		t     float32
+		res   math.Vec4f
+	}{
+		// from the outside
+		{
+			NewPointLight(math.NewPoint(-10, 10, -10), math.NewRGB(1, 1, 1)),
+			math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)),
+			0, 4,
+			math.NewRGB(0.38066, 0.47583, 0.2855),
+		},
+		// from the inside, which differs slightly from the book, because the light is evaluated at the OverPoint
+		{
+			NewPointLight(math.NewPoint(0, 0.25, 0), math.NewRGB(1, 1, 1)),
+			math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 0, 1)),
+			1, 0.5,
+			math.NewRGB(0.90466, 0.90466, 0.90466),
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := defaultWorld()
+			w.Lights[0] = tt.light
+			hit := Intersection{T: tt.t, Object: w.Objects[tt.obj]}
+			comps := PrepareComputations(&hit, &tt.ray, nil)
+			res := w.ShadeHit(&comps, DefaultMaxDepth)
+			if !equalsApprox(&res, &tt.res) {
+				t.Errorf("ShadeHit() = %v, want %v", res, tt.res)
+			}
+		})
+	}
+}
+
+func TestWorld_ShadeHit_PatternAcne(t *testing.T) {
+	// the computed points scatter around the surface, which must not flip the pattern at y = 0
+	w := NewWorld()
+	w.Lights = []PointLight{NewPointLight(math.NewPoint(0, 10, 0), math.NewRGB(1, 1, 1))}
+	floor := NewPlane()
+	floor.Material().Pattern = NewCheckerPattern(NewSolidPattern(white), NewSolidPattern(black))
+	floor.Material().Ambient = 1
+	floor.Material().Diffuse = 0
+	floor.Material().Specular = 0
+	w.Objects = []Shape{floor}
+
+	origin := math.NewPoint(0.1, 1.3, -5.7)
+	for i := 0; i < 40; i++ {
+		for j := 0; j < 40; j++ {
+			// the centers of the cells are far away from the edges of the pattern
+			target := math.NewPoint(float32(i-20)+0.5, 0, float32(j-20)+0.5)
+			want := white
+			if (i+j)%2 == 1 {
+				want = black
+			}
+
+			direction := target
+			direction.Sub(&origin)
+			direction.Normalize()
+			r := math.NewRay(origin, direction)
+			if got := w.ColorAt(&r); !equalsColor(&got, &want) {
+				t.Errorf("ColorAt(%v) = %v, want %v", target, got, want)
+			}
+		}
+	}
+}
+
+func TestWorld_ColorAt(t *testing.T) {
+	w := defaultWorld()
+	tests := []struct {
+		ray math.Ray
+		res math.Vec4f
+	}{
+		// a miss
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 1, 0)), math.NewRGB(0, 0, 0)},
+		// a hit
+		{math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)), math.NewRGB(0.38066, 0.47583, 0.2855)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := w.ColorAt(&tt.ray)
+			if !equalsApprox(&res, &tt.res) {
+				t.Errorf("ColorAt() = %v, want %v", res, tt.res)
+			}
+		})
+	}
+
+	// the hit is behind the ray, between the inner and outer sphere
+	outer, inner := w.Objects[0], w.Objects[1]
+	outer.Material().Ambient = 1
+	inner.Material().Ambient = 1
+	r := math.NewRay(math.NewPoint(0, 0, 0.75), math.NewVector(0, 0, -1))
+	res := w.ColorAt(&r)
+	if !equalsApprox(&res, &inner.Material().Color) {
+		t.Errorf("ColorAt() = %v, want %v", res, inner.Material().Color)
+	}
+}
+
+func TestWorld_IsShadowed(t *testing.T) {
+	w := defaultWorld()
+	tests := []struct {
+		point math.Vec4f
+		want  bool
+	}{
+		// nothing is collinear with point and light
+		{math.NewPoint(0, 10, 0), false},
+		// an object is between the point and the light
+		{math.NewPoint(10, -10, 10), true},
+		// the object is behind the light
+		{math.NewPoint(-20, 20, -20), false},
+		// the object is behind the point
+		{math.NewPoint(-2, 2, -2), false},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := w.IsShadowed(&w.Lights[0], &tt.point); got != tt.want {
+				t.Errorf("IsShadowed(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorld_ShadeHitInShadow(t *testing.T) {
+	s1 := NewSphere()
+	s2 := NewSphere()
+	s2.SetTransform(math.Translation(0, 0, 10))
+
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(math.NewPoint(0, 0, -10), math.NewRGB(1, 1, 1)))
+	w.Objects = append(w.Objects, s1, s2)
+
+	r := math.NewRay(math.NewPoint(0, 0, 5), math.NewVector(0, 0, 1))
+	hit := Intersection{T: 4, Object: s2}
+	comps := PrepareComputations(&hit, &r, nil)
+	res := w.ShadeHit(&comps, DefaultMaxDepth)
+	want := math.NewRGB(0.1, 0.1, 0.1)
+	if !equalsApprox(&res, &want) {
+		t.Errorf("ShadeHit() = %v, want %v", res, want)
+	}
+}
+
+func TestWorld_ReflectedColor(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+
+	// a non reflective material
+	w := defaultWorld()
+	r := math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 0, 1))
+	inner := w.Objects[1]
+	inner.Material().Ambient = 1
+	hit := Intersection{T: 1, Object: inner}
+	comps := PrepareComputations(&hit, &r, nil)
+	res := w.ReflectedColor(&comps, DefaultMaxDepth)
+	if !res.Equals(&black) {
+		t.Errorf("ReflectedColor() = %v, want %v", res, black)
+	}
+
+	tests := []struct {
+		remaining int
+		reflected math.Vec4f
+		shaded    math.Vec4f
+	}{
+		{DefaultMaxDepth, math.NewRGB(0.19032, 0.2379, 0.14274), math.NewRGB(0.87677, 0.92436, 0.82918)},
+		{0, black, math.NewRGB(0.68643, 0.68643, 0.68643)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := defaultWorld()
+			p := NewPlane()
+			p.Material().Reflective = 0.5
+			p.SetTransform(math.Translation(0, -1, 0))
+			w.Objects = append(w.Objects, p)
+
+			r := math.NewRay(math.NewPoint(0, 0, -3), math.NewVector(0, -sqrt2, sqrt2))
+			hit := Intersection{T: math.Sqrt(2), Object: p}
+			comps := PrepareComputations(&hit, &r, nil)
+			if res := w.ReflectedColor(&comps, tt.remaining); !equalsColor(&res, &tt.reflected) {
+				t.Errorf("ReflectedColor() = %v, want %v", res, tt.reflected)
+			}
+
+			if res := w.ShadeHit(&comps, tt.remaining); !equalsColor(&res, &tt.shaded) {
+				t.Errorf("ShadeHit() = %v, want %v", res, tt.shaded)
+			}
+		})
+	}
+}
+
+func TestWorld_ColorAtMutuallyReflective(t *testing.T) {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(math.NewPoint(0, 0, 0), math.NewRGB(1, 1, 1)))
+
+	lower := NewPlane()
+	lower.Material().Reflective = 1
+	lower.SetTransform(math.Translation(0, -1, 0))
+
+	upper := NewPlane()
+	upper.Material().Reflective = 1
+	upper.SetTransform(math.Translation(0, 1, 0))
+
+	w.Objects = append(w.Objects, lower, upper)
+
+	// must terminate
+	r := math.NewRay(math.NewPoint(0, 0, 0), math.NewVector(0, 1, 0))
+	w.ColorAt(&r)
+}
+
+func TestWorld_RefractedColor(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		name      string
+		prepare   func(w *World)
+		ray       math.Ray
+		ts        []float32
+		hit       int
+		remaining int
+		res       math.Vec4f
+	}{
+		{
+			name:      "opaque",
+			prepare:   func(w *World) {},
+			ray:       math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)),
+			ts:        []float32{4, 6},
+			remaining: DefaultMaxDepth,
+			res:       black,
+		},
+		{
+			name: "maximum recursion depth",
+			prepare: func(w *World) {
+				w.Objects[0].Material().Transparency = 1
+				w.Objects[0].Material().RefractiveIndex = 1.5
+			},
+			ray:       math.NewRay(math.NewPoint(0, 0, -5), math.NewVector(0, 0, 1)),
+			ts:        []float32{4, 6},
+			remaining: 0,
+			res:       black,
+		},
+		{
+			name: "total internal reflection",
+			prepare: func(w *World) {
+				w.Objects[0].Material().Transparency = 1
+				w.Objects[0].Material().RefractiveIndex = 1.5
+			},
+			ray:       math.NewRay(math.NewPoint(0, 0, sqrt2), math.NewVector(0, 1, 0)),
+			ts:        []float32{-sqrt2, sqrt2},
+			hit:       1,
+			remaining: DefaultMaxDepth,
+			res:       black,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := defaultWorld()
+			tt.prepare(w)
+			var xs Intersections
+			for _, v := range tt.ts {
+				xs = append(xs, Intersection{T: v, Object: w.Objects[0]})
+			}
+
+			comps := PrepareComputations(&xs[tt.hit], &tt.ray, xs)
+			if res := w.RefractedColor(&comps, tt.remaining); !equalsColor(&res, &tt.res) {
+				t.Errorf("RefractedColor() = %v, want %v", res, tt.res)
+			}
+		})
+	}
+
+	// the refracted ray hits the inner sphere, which reveals the point
+	w := defaultWorld()
+	a, b := w.Objects[0], w.Objects[1]
+	a.Material().Ambient = 1
+	a.Material().Pattern = newTestPattern()
+	b.Material().Transparency = 1
+	b.Material().RefractiveIndex = 1.5
+	r := math.NewRay(math.NewPoint(0, 0, 0.1), math.NewVector(0, 1, 0))
+	xs := Intersections{{T: -0.9899, Object: a}, {T: -0.4899, Object: b}, {T: 0.4899, Object: b}, {T: 0.9899, Object: a}}
+	comps := PrepareComputations(&xs[2], &r, xs)
+	want := math.NewRGB(0, 0.99787, 0.04747) // the book has 0, 0.99888, 0.04725 with a smaller OverPoint bias
+	if res := w.RefractedColor(&comps, DefaultMaxDepth); !equalsColor(&res, &want) {
+		t.Errorf("RefractedColor() = %v, want %v", res, want)
+	}
+}
+
+func TestWorld_ShadeHitTransparent(t *testing.T) {
+	sqrt2 := math.Sqrt(2) / 2
+	tests := []struct {
+		reflective float32
+		res        math.Vec4f
+	}{
+		{0, math.NewRGB(0.93642, 0.68642, 0.68642)},
+		// with the fresnel effect
+		{0.5, math.NewRGB(0.93391, 0.69643, 0.69243)},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := defaultWorld()
+			floor := NewPlane()
+			floor.SetTransform(math.Translation(0, -1, 0))
+			floor.Material().Reflective = tt.reflective
+			floor.Material().Transparency = 0.5
+			floor.Material().RefractiveIndex = 1.5
+
+			ball := NewSphere()
+			ball.Material().Color = math.NewRGB(1, 0, 0)
+			ball.Material().Ambient = 0.5
+			ball.SetTransform(math.Translation(0, -3.5, -0.5))
+
+			w.Objects = append(w.Objects, floor, ball)
+
+			r := math.NewRay(math.NewPoint(0, 0, -3), math.NewVector(0, -sqrt2, sqrt2))
+			xs := Intersections{{T: math.Sqrt(2), Object: floor}}
+			comps := PrepareComputations(&xs[0], &r, xs)
+			if res := w.ShadeHit(&comps, DefaultMaxDepth); !equalsColor(&res, &tt.res) {
+				t.Errorf("ShadeHit() = %v, want %v", res, tt.res)
+			}
+		})
+	}
+}